@@ -80,7 +80,9 @@ func (s *StepKeyPair) Run(ctx context.Context, state multistep.StateBag) multist
 	// directory.
 	if s.Debug {
 		ui.Message(fmt.Sprintf("Saving key for debug purposes: %s", s.DebugKeyPath))
-		f, err := os.Create(s.DebugKeyPath)
+		// Create the key with restrictive permissions so that it's never
+		// readable by others, even briefly.
+		f, err := os.OpenFile(s.DebugKeyPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 		if err != nil {
 			err = fmt.Errorf("Error saving debug key: %s", err)
 			ui.Error(err.Error())
@@ -97,7 +99,7 @@ func (s *StepKeyPair) Run(ctx context.Context, state multistep.StateBag) multist
 			return multistep.ActionHalt
 		}
 
-		// Chmod it so that it is SSH ready
+		// Chmod it so that it is SSH ready, in case the file already existed
 		if runtime.GOOS != "windows" {
 			if err := f.Chmod(0600); err != nil {
 				err = fmt.Errorf("Error setting permissions of debug key: %s", err)
@@ -0,0 +1,65 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/communicator"
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func testState() multistep.StateBag {
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", &packersdk.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	})
+	return state
+}
+
+func TestStepKeyPair_Temporary(t *testing.T) {
+	state := testState()
+	comm := &communicator.Config{}
+	step := &StepKeyPair{Comm: comm}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if len(comm.SSHPublicKey) == 0 {
+		t.Fatal("Expected a temporary public key")
+	}
+	if _, ok := state.GetOk("privateKey"); !ok {
+		t.Fatal("Expected the temporary private key in the state")
+	}
+}
+
+func TestStepKeyPair_DebugKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer_key_pair")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	state := testState()
+	path := filepath.Join(dir, "debug.pem")
+	step := &StepKeyPair{Debug: true, Comm: &communicator.Config{}, DebugKeyPath: path}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected the debug key to be saved: %s", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the debug key to have permissions 0600, got %o", info.Mode().Perm())
+	}
+}
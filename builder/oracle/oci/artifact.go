@@ -2,11 +2,26 @@ package oci
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
 	"github.com/oracle/oci-go-sdk/core"
 )
 
+// artifactMetadataStateKey names the artifact state holding its metadata as
+// JSON, for automation that would otherwise scrape the build output.
+const artifactMetadataStateKey = "metadata"
+
+// artifactMetadata is the JSON document of the metadata state.
+type artifactMetadata struct {
+	ImageID       string                            `json:"image_ocid"`
+	Region        string                            `json:"region"`
+	CompartmentID string                            `json:"compartment_ocid"`
+	FreeformTags  map[string]string                 `json:"freeform_tags,omitempty"`
+	DefinedTags   map[string]map[string]interface{} `json:"defined_tags,omitempty"`
+}
+
 // Artifact is an artifact implementation that contains a built Custom Image.
 type Artifact struct {
 	Image  core.Image
@@ -29,12 +44,20 @@ func (a *Artifact) Files() []string {
 	return nil
 }
 
-// Id returns the OCID of the associated Image.
+// Id returns the OCID of the associated Image, or "" if skip_create_image
+// meant that no image was created.
 func (a *Artifact) Id() string {
+	if a.Image.Id == nil {
+		return ""
+	}
 	return *a.Image.Id
 }
 
 func (a *Artifact) String() string {
+	if a.Image.Id == nil {
+		return fmt.Sprintf("No image was created in region '%v' as skip_create_image is set", a.Region)
+	}
+
 	var displayName string
 	if a.Image.DisplayName != nil {
 		displayName = *a.Image.DisplayName
@@ -46,12 +69,55 @@ func (a *Artifact) String() string {
 	)
 }
 
-// State ...
+// State returns the named state, with "metadata" being the image's OCID,
+// region, compartment and tags as a JSON string. It is nil if no image was
+// created.
 func (a *Artifact) State(name string) interface{} {
+	if name == artifactMetadataStateKey {
+		return a.metadata()
+	}
 	return a.StateData[name]
 }
 
+func (a *Artifact) metadata() interface{} {
+	if a.Image.Id == nil {
+		return nil
+	}
+
+	metadata := artifactMetadata{
+		ImageID:      *a.Image.Id,
+		Region:       a.Region,
+		FreeformTags: a.Image.FreeformTags,
+		DefinedTags:  a.Image.DefinedTags,
+	}
+	if a.Image.CompartmentId != nil {
+		metadata.CompartmentID = *a.Image.CompartmentId
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil
+	}
+	return string(data)
+}
+
+// reportMachine writes the image's OCID, compartment and region as
+// machine-readable "artifact" output, which -machine-readable builds print.
+func (a *Artifact) reportMachine(ui packersdk.Ui) {
+	if a.Image.Id == nil {
+		return
+	}
+	ui.Machine("artifact", "image_ocid", *a.Image.Id)
+	if a.Image.CompartmentId != nil {
+		ui.Machine("artifact", "compartment_ocid", *a.Image.CompartmentId)
+	}
+	ui.Machine("artifact", "region", a.Region)
+}
+
 // Destroy deletes the custom image associated with the artifact.
 func (a *Artifact) Destroy() error {
+	if a.Image.Id == nil {
+		return nil
+	}
 	return a.driver.DeleteImage(context.TODO(), *a.Image.Id)
 }
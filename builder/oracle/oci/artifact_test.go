@@ -1,9 +1,13 @@
 package oci
 
 import (
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/oracle/oci-go-sdk/core"
 )
 
 func TestArtifactImpl(t *testing.T) {
@@ -39,3 +43,90 @@ func TestArtifactState_StateData(t *testing.T) {
 		t.Fatalf("Bad: State should be nil for nil StateData")
 	}
 }
+
+func TestArtifact_NoImage(t *testing.T) {
+	artifact := &Artifact{Region: "us-phoenix-1"}
+
+	if id := artifact.Id(); id != "" {
+		t.Fatalf("Bad: Id should be empty without an image, got %s", id)
+	}
+	if s := artifact.String(); !strings.Contains(s, "No image was created") {
+		t.Fatalf("Bad: String should say no image was created, got %s", s)
+	}
+	if err := artifact.Destroy(); err != nil {
+		t.Fatalf("Bad: Destroy should be a no-op without an image, got %s", err)
+	}
+	if metadata := artifact.State("metadata"); metadata != nil {
+		t.Fatalf("Bad: metadata should be nil without an image, got %v", metadata)
+	}
+}
+
+func TestArtifactState_Metadata(t *testing.T) {
+	id := "ocid1.image.oc1.phx.aaaa"
+	compartmentID := "ocid1.compartment.oc1..aaaa"
+	artifact := &Artifact{
+		Image: core.Image{
+			Id:            &id,
+			CompartmentId: &compartmentID,
+			FreeformTags:  map[string]string{"Name": "packer"},
+			DefinedTags:   map[string]map[string]interface{}{"Operations": {"CostCenter": "42"}},
+		},
+		Region: "us-phoenix-1",
+	}
+
+	raw, ok := artifact.State("metadata").(string)
+	if !ok {
+		t.Fatalf("Bad: metadata should be a JSON string, got %#v", artifact.State("metadata"))
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+		t.Fatalf("Bad: metadata should be valid JSON, got %s: %s", raw, err)
+	}
+	if metadata["image_ocid"] != id || metadata["region"] != "us-phoenix-1" || metadata["compartment_ocid"] != compartmentID {
+		t.Errorf("Bad: unexpected metadata %s", raw)
+	}
+	if tags, _ := metadata["freeform_tags"].(map[string]interface{}); tags["Name"] != "packer" {
+		t.Errorf("Bad: metadata should include the freeform tags, got %s", raw)
+	}
+	if tags, _ := metadata["defined_tags"].(map[string]interface{}); tags["Operations"] == nil {
+		t.Errorf("Bad: metadata should include the defined tags, got %s", raw)
+	}
+}
+
+// machineUi records the machine-readable output written to it.
+type machineUi struct {
+	packersdk.BasicUi
+	machine [][]string
+}
+
+func (u *machineUi) Machine(t string, args ...string) {
+	u.machine = append(u.machine, append([]string{t}, args...))
+}
+
+func TestArtifactReportMachine(t *testing.T) {
+	id := "ocid1.image.oc1.phx.aaaa"
+	compartmentID := "ocid1.compartment.oc1..aaaa"
+	artifact := &Artifact{
+		Image:  core.Image{Id: &id, CompartmentId: &compartmentID},
+		Region: "us-phoenix-1",
+	}
+
+	ui := &machineUi{}
+	artifact.reportMachine(ui)
+
+	expected := [][]string{
+		{"artifact", "image_ocid", id},
+		{"artifact", "compartment_ocid", compartmentID},
+		{"artifact", "region", "us-phoenix-1"},
+	}
+	if !reflect.DeepEqual(ui.machine, expected) {
+		t.Errorf("Bad: expected machine-readable output %v, got %v", expected, ui.machine)
+	}
+
+	ui = &machineUi{}
+	(&Artifact{Region: "us-phoenix-1"}).reportMachine(ui)
+	if len(ui.machine) != 0 {
+		t.Errorf("Bad: expected no machine-readable output without an image, got %v", ui.machine)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	"github.com/hashicorp/packer-plugin-sdk/multistep/commonsteps"
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/packerbuilderdata"
 	ocommon "github.com/hashicorp/packer/builder/oracle/common"
 	"github.com/oracle/oci-go-sdk/core"
 )
@@ -35,11 +36,25 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 		return nil, nil, err
 	}
 
-	return nil, nil, nil
+	if b.config.ValidateCloudResources {
+		driver, err := NewDriverOCI(&b.config, nil)
+		if err == nil {
+			err = b.config.validateCloudResources(context.TODO(), driver)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The instance's OCID and IP, available to provisioners as
+	// build.InstanceID and build.InstanceIP.
+	generatedData := []string{"InstanceID", "InstanceIP"}
+
+	return generatedData, b.config.warnings(), nil
 }
 
 func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook) (packersdk.Artifact, error) {
-	driver, err := NewDriverOCI(&b.config)
+	driver, err := NewDriverOCI(&b.config, ui)
 	if err != nil {
 		return nil, err
 	}
@@ -51,15 +66,30 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 	state.Put("hook", hook)
 	state.Put("ui", ui)
 
+	generatedData := &packerbuilderdata.GeneratedData{State: state}
+
 	// Build the steps
 	steps := []multistep.Step{
-		&ocommon.StepKeyPair{
-			Debug:        b.config.PackerDebug,
-			Comm:         &b.config.Comm,
-			DebugKeyPath: fmt.Sprintf("oci_%s.pem", b.config.PackerBuildName),
+		&stepValidateTagNamespaces{},
+		&stepValidateAvailabilityDomain{},
+		&stepKeyPair{
+			StepKeyPair: ocommon.StepKeyPair{
+				Debug:        b.config.PackerDebug,
+				Comm:         &b.config.Comm,
+				DebugKeyPath: fmt.Sprintf("oci_%s.pem", b.config.PackerBuildName),
+			},
 		},
-		&stepCreateInstance{},
-		&stepInstanceInfo{},
+		&stepImportImage{},
+		&stepMarketplaceImage{},
+		&stepCreateInstance{
+			GeneratedData: generatedData,
+		},
+		&stepAttachVolumes{},
+		&stepAttachVnics{},
+		&stepInstanceInfo{
+			GeneratedData: generatedData,
+		},
+		&stepWaitForAgent{},
 		&stepGetDefaultCredentials{
 			Debug:     b.config.PackerDebug,
 			Comm:      &b.config.Comm,
@@ -75,6 +105,8 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 			Comm: &b.config.Comm,
 		},
 		&stepImage{},
+		&stepExportImage{},
+		&stepMoveImage{},
 	}
 
 	// Run the steps
@@ -91,6 +123,14 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 		return nil, err
 	}
 
+	if b.config.SkipCreateImage {
+		return &Artifact{
+			Region:    region,
+			driver:    driver,
+			StateData: map[string]interface{}{"generated_data": state.Get("generated_data")},
+		}, nil
+	}
+
 	image, ok := state.GetOk("image")
 	if !ok {
 		return nil, err
@@ -104,6 +144,18 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 		StateData: map[string]interface{}{"generated_data": state.Get("generated_data")},
 	}
 
+	if uri, ok := state.GetOk("image_export_uri"); ok {
+		artifact.StateData["image_export_uri"] = uri
+	}
+	if artifact.Image.SizeInMBs != nil {
+		artifact.StateData["image_size_in_mbs"] = *artifact.Image.SizeInMBs
+	}
+	if artifact.Image.BaseImageId != nil {
+		artifact.StateData["base_image_ocid"] = *artifact.Image.BaseImageId
+	}
+
+	artifact.reportMachine(ui)
+
 	return artifact, nil
 }
 
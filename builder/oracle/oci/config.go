@@ -1,18 +1,30 @@
-//go:generate mapstructure-to-hcl2 -type Config,CreateVNICDetails,ListImagesRequest
+//go:generate mapstructure-to-hcl2 -type Config,BlockVolume,CreateVNICDetails,ListImagesRequest,SecondaryVnic
 
 package oci
 
 import (
+	"context"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"os/user"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/go-ini/ini"
 	"github.com/hashicorp/packer-plugin-sdk/common"
 	"github.com/hashicorp/packer-plugin-sdk/communicator"
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
@@ -21,6 +33,57 @@ import (
 	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
 	ocicommon "github.com/oracle/oci-go-sdk/common"
 	ociauth "github.com/oracle/oci-go-sdk/common/auth"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// instancePackerTagKey is the freeform tag added to the build instance to
+// identify it as created by Packer.
+const instancePackerTagKey = "Packer"
+
+// imageBaseImageTagKey is the freeform tag holding the OCID of the base image
+// the image was built from, when TagBaseImage is set.
+const imageBaseImageTagKey = "base_image"
+
+// sshAuthorizedKeysMetadataKey is the instance metadata key holding the
+// communicator's public key. It can't be set through metadata.
+const sshAuthorizedKeysMetadataKey = "ssh_authorized_keys"
+
+// securityTokenKeyIDPrefix prefixes the key ID of requests signed with a
+// session security token rather than an API key.
+const securityTokenKeyIDPrefix = "ST$"
+
+// defaultAPIMaxRetries is the default number of times a failed OCI API
+// request is retried.
+const defaultAPIMaxRetries = 9
+
+// defaultHTTPTimeout bounds a single OCI API request, as the SDK's own HTTP
+// client does.
+const defaultHTTPTimeout = 60 * time.Second
+
+// bareMetalInstanceLaunchTimeout is the least time waited for an instance of
+// a bare metal shape to launch when state_timeout is set, as bare metal
+// instances take far longer to provision than virtual machines.
+const bareMetalInstanceLaunchTimeout = time.Hour
+
+// defaultAPIRetryableStatusCodes are the HTTP status codes of the throttling
+// and transient server errors that OCI API requests are retried after.
+var defaultAPIRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+}
+
+// Supported values of auth_type.
+const (
+	authTypeAPIKey            = "api_key"
+	authTypeInstancePrincipal = "instance_principal"
+)
+
+// Supported values of ip_version.
+const (
+	ipVersionIPv4 = "ipv4"
+	ipVersionIPv6 = "ipv6"
 )
 
 type CreateVNICDetails struct {
@@ -46,6 +109,28 @@ type ListImagesRequest struct {
 	Shape                  *string `mapstructure:"shape"`
 }
 
+// selectsImages reports whether any of the fields that pick an image were
+// set. CompartmentId and Shape only narrow the search and are defaulted by
+// Prepare, so they don't count.
+func (r ListImagesRequest) selectsImages() bool {
+	return r.DisplayName != nil || r.DisplayNameSearch != nil ||
+		r.OperatingSystem != nil || r.OperatingSystemVersion != nil
+}
+
+type BlockVolume struct {
+	// fields that can be specified under "block_volumes"
+	SizeInGBs      int64                             `mapstructure:"size_in_gbs"`
+	AttachmentType string                            `mapstructure:"attachment_type"`
+	FreeformTags   map[string]string                 `mapstructure:"freeform_tags"`
+	DefinedTags    map[string]map[string]interface{} `mapstructure:"defined_tags"`
+}
+
+type SecondaryVnic struct {
+	// fields that can be specified under "secondary_vnics"
+	SubnetID string   `mapstructure:"subnet_ocid"`
+	NsgIDs   []string `mapstructure:"nsg_ocids"`
+}
+
 type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 	Comm                communicator.Config `mapstructure:",squash"`
@@ -61,9 +146,15 @@ type Config struct {
 	// - Region
 	// - Fingerprint
 	// - KeyFile
+	// - KeyContent
 	// - PassPhrase
 	InstancePrincipals bool `mapstructure:"use_instance_principals"`
 
+	// AuthType selects how to authenticate against the OCI API, either
+	// "api_key" (the default) or "instance_principal". Setting
+	// use_instance_principals implies "instance_principal".
+	AuthType string `mapstructure:"auth_type"`
+
 	AccessCfgFile        string `mapstructure:"access_cfg_file"`
 	AccessCfgFileAccount string `mapstructure:"access_cfg_file_account"`
 
@@ -76,8 +167,57 @@ type Config struct {
 	PassPhrase   string `mapstructure:"pass_phrase"`
 	UsePrivateIP bool   `mapstructure:"use_private_ip"`
 
-	AvailabilityDomain string `mapstructure:"availability_domain"`
-	CompartmentID      string `mapstructure:"compartment_ocid"`
+	// KeyContent is the PEM encoded API signing key, as an alternative to
+	// reading it from key_file.
+	KeyContent string `mapstructure:"key_content"`
+
+	// IPVersion is the IP version of the address to connect to the instance
+	// on, "ipv4" (the default) or "ipv6" for a dual-stack subnet.
+	IPVersion string `mapstructure:"ip_version"`
+
+	AvailabilityDomain string  `mapstructure:"availability_domain"`
+	FaultDomain        *string `mapstructure:"fault_domain"`
+	CompartmentID      string  `mapstructure:"compartment_ocid"`
+
+	// CompartmentName is looked up in the tenancy to find the
+	// compartment_ocid when that isn't set.
+	CompartmentName string `mapstructure:"compartment_name"`
+
+	// NetworkCompartmentID is the compartment that the instance's VNICs are
+	// looked up in, for when networking lives apart from compartment_ocid.
+	NetworkCompartmentID string `mapstructure:"network_compartment_ocid"`
+
+	// IdentityCompartmentID is the compartment that availability domains,
+	// compartments and tag namespaces are looked up in, for users who can
+	// only read them in part of the tenancy.
+	IdentityCompartmentID string `mapstructure:"identity_compartment_ocid"`
+
+	// DedicatedVmHostID launches the instance on the given dedicated virtual
+	// machine host, which must be in availability_domain.
+	DedicatedVmHostID string `mapstructure:"dedicated_vm_host_ocid"`
+
+	// CapacityReservationID launches the instance into the given capacity
+	// reservation rather than on-demand capacity.
+	CapacityReservationID string `mapstructure:"capacity_reservation_ocid"`
+
+	// Preemptible launches the instance on preemptible capacity, which OCI
+	// may reclaim, terminating the instance, at any time.
+	Preemptible bool `mapstructure:"preemptible"`
+
+	// DisableLegacyImdsEndpoints launches the instance with only the v2
+	// instance metadata service endpoints, which require a request header.
+	DisableLegacyImdsEndpoints bool `mapstructure:"disable_legacy_imds_endpoints"`
+
+	// SecureBoot, MeasuredBoot and TrustedPlatformModule launch a shielded
+	// instance, which only some shapes support.
+	SecureBoot            bool `mapstructure:"secure_boot"`
+	MeasuredBoot          bool `mapstructure:"measured_boot"`
+	TrustedPlatformModule bool `mapstructure:"trusted_platform_module"`
+
+	// AgentDisabledPlugins are Oracle Cloud Agent plugins disabled when the
+	// instance is launched, or all of them with AgentAreAllPluginsDisabled.
+	AgentDisabledPlugins       []string `mapstructure:"agent_disabled_plugins"`
+	AgentAreAllPluginsDisabled bool     `mapstructure:"agent_are_all_plugins_disabled"`
 
 	// Image
 	BaseImageID        string            `mapstructure:"base_image_ocid"`
@@ -86,31 +226,225 @@ type Config struct {
 	ImageCompartmentID string            `mapstructure:"image_compartment_ocid"`
 	LaunchMode         string            `mapstructure:"image_launch_mode"`
 
+	// FinalImageCompartmentID is where the image is moved once the build has
+	// otherwise succeeded, after any export.
+	FinalImageCompartmentID string `mapstructure:"final_image_compartment_ocid"`
+
+	// ImageNamePrefix and ImageNameTimestampFormat, a Go time layout, make
+	// up the default image_name as "<prefix>-<UTC time>", which is easier to
+	// read in the Console than the default Unix timestamp.
+	ImageNamePrefix          string `mapstructure:"image_name_prefix"`
+	ImageNameTimestampFormat string `mapstructure:"image_name_timestamp_format"`
+
+	// ImageCapabilitySchemaID is an existing image capability schema whose
+	// capabilities are given to the resulting image.
+	ImageCapabilitySchemaID string `mapstructure:"image_capability_schema_ocid"`
+
+	// InheritCompartmentTags gives the image the defined tags of the tag
+	// defaults of ImageCompartmentID, unless ImageDefinedTags sets them.
+	InheritCompartmentTags bool `mapstructure:"inherit_compartment_tags"`
+
+	// ImageOperatingSystem and ImageOperatingSystemVersion are set on the
+	// resulting image for sources that OCI can't identify the OS of.
+	ImageOperatingSystem        string `mapstructure:"image_operating_system"`
+	ImageOperatingSystemVersion string `mapstructure:"image_operating_system_version"`
+
+	// SourceBootVolumeID launches the instance from an existing, detached boot
+	// volume instead of a base image. The boot volume is preserved when the
+	// instance is terminated. CreateImageDetails can only take an instance or
+	// an Object Storage object as its source, so a boot volume can't be turned
+	// into an image without launching an instance from it.
+	SourceBootVolumeID string `mapstructure:"source_boot_volume_ocid"`
+
+	// MarketplaceListingID launches the instance from the image of a
+	// Marketplace listing, at MarketplacePackageVersion or else the most
+	// recently published version. MarketplaceAcceptAgreement subscribes the
+	// compartment to the listing, accepting its terms, before the launch.
+	MarketplaceListingID       string `mapstructure:"marketplace_listing_ocid"`
+	MarketplacePackageVersion  string `mapstructure:"marketplace_package_version"`
+	MarketplaceAcceptAgreement bool   `mapstructure:"marketplace_accept_agreement"`
+
+	// UseInstanceID provisions and images an existing instance instead of
+	// launching one. The instance is left running when the build finishes.
+	UseInstanceID string `mapstructure:"use_instance_ocid"`
+
+	// Base image import (OPTIONAL)
+	// When base_image_import_bucket is set the base image is first imported
+	// from the given Object Storage object, such as one exported by
+	// image_export_bucket.
+	BaseImageImportBucket    string `mapstructure:"base_image_import_bucket"`
+	BaseImageImportNamespace string `mapstructure:"base_image_import_namespace"`
+	BaseImageImportName      string `mapstructure:"base_image_import_name"`
+	BaseImageImportFormat    string `mapstructure:"base_image_import_format"`
+
+	// Image export (OPTIONAL)
+	// When image_export_bucket is set the resulting image is exported to the
+	// given Object Storage bucket once it has been created.
+	ImageExportBucket    string `mapstructure:"image_export_bucket"`
+	ImageExportNamespace string `mapstructure:"image_export_namespace"`
+	ImageExportName      string `mapstructure:"image_export_name"`
+	ImageExportFormat    string `mapstructure:"image_export_format"`
+
 	// Instance
 	InstanceName        *string                           `mapstructure:"instance_name"`
 	InstanceTags        map[string]string                 `mapstructure:"instance_tags"`
 	InstanceDefinedTags map[string]map[string]interface{} `mapstructure:"instance_defined_tags"`
 	Shape               string                            `mapstructure:"shape"`
+	ShapeOCPUs          float32                           `mapstructure:"shape_ocpus"`
+	ShapeMemoryInGBs    float32                           `mapstructure:"shape_memory_in_gbs"`
 	BootVolumeSizeInGBs int64                             `mapstructure:"disk_size"`
 
+	// BootVolumeVpusPerGB is the performance of the build instance's boot
+	// volume, in volume performance units per GB.
+	BootVolumeVpusPerGB int64 `mapstructure:"boot_volume_vpus_per_gb"`
+
+	// KmsKeyID is the Vault key the build instance's boot volume is
+	// encrypted with. The image created from it is not encrypted with it.
+	KmsKeyID string `mapstructure:"kms_key_ocid"`
+
+	// Launch options (OPTIONAL) for images, such as those imported from other
+	// clouds, that need specific emulation.
+	LaunchNetworkType    string `mapstructure:"launch_network_type"`
+	LaunchBootVolumeType string `mapstructure:"launch_boot_volume_type"`
+	LaunchFirmware       string `mapstructure:"launch_firmware"`
+
+	// BlockVolumes are created and attached to the instance for the
+	// provisioners to use, then detached and deleted. They are not part of
+	// the image.
+	BlockVolumes []BlockVolume `mapstructure:"block_volumes"`
+
+	// SecondaryVnics are attached to the instance once it is running, and
+	// detached before it is terminated.
+	SecondaryVnics []SecondaryVnic `mapstructure:"secondary_vnics"`
+
+	// DebugKeepInstance leaves the instance running when the build fails so
+	// that it can be inspected.
+	DebugKeepInstance bool `mapstructure:"debug_keep_instance"`
+
+	// SkipCreateImage runs the provisioners without creating an image, for
+	// iterating on provisioning. With DebugKeepInstance the instance is kept
+	// even when the build succeeds.
+	SkipCreateImage bool `mapstructure:"skip_create_image"`
+
+	// WaitForTermination makes cleanup wait for the instance to be
+	// TERMINATED rather than returning once termination has been requested.
+	WaitForTermination bool `mapstructure:"wait_for_termination"`
+
+	// StopBeforeImage stops the instance before the image is created from
+	// it, so that its file systems are consistent.
+	StopBeforeImage bool `mapstructure:"stop_before_image"`
+
+	// ValidateCloudResources makes validation check with the OCI API that the
+	// base image, subnet and shape exist and can be used together. Only
+	// read-only calls are made.
+	ValidateCloudResources bool `mapstructure:"validate_cloud_resources"`
+
 	// Metadata optionally contains custom metadata key/value pairs provided in the
 	// configuration. While this can be used to set metadata["user_data"] the explicit
-	// "user_data" and "user_data_file" values will have precedence.
+	// "user_data" and "user_data_file" values will have precedence. The
+	// "ssh_authorized_keys" key is reserved for the communicator's key.
 	// An instance's metadata can be obtained from at http://169.254.169.254 on the
 	// launched instance.
 	Metadata map[string]string `mapstructure:"metadata"`
 
+	// ExtendedMetadata optionally contains metadata whose values may be nested
+	// objects rather than strings. It is sent alongside Metadata.
+	ExtendedMetadata map[string]interface{} `mapstructure:"extended_metadata"`
+
 	// UserData and UserDataFile file are both optional and mutually exclusive.
 	UserData     string `mapstructure:"user_data"`
 	UserDataFile string `mapstructure:"user_data_file"`
 
+	// Nameservers and SearchDomains are rendered into a cloud-init user_data
+	// that configures the instance's resolv.conf, so cannot be combined with
+	// user_data or user_data_file.
+	Nameservers   []string `mapstructure:"nameservers"`
+	SearchDomains []string `mapstructure:"search_domains"`
+
+	// StatePollInterval is how often the state of the instance and image is
+	// polled while waiting on them, and StateTimeout bounds the wait. When
+	// StatePollMultiplier is greater than 1 the interval backs off
+	// exponentially from StatePollInterval up to StatePollMax.
+	// StatePollInitial is an alias of StatePollInterval.
+	StatePollInterval   time.Duration `mapstructure:"state_poll_interval"`
+	StatePollInitial    time.Duration `mapstructure:"state_poll_initial"`
+	StatePollMax        time.Duration `mapstructure:"state_poll_max"`
+	StatePollMultiplier float64       `mapstructure:"state_poll_multiplier"`
+	StateTimeout        time.Duration `mapstructure:"state_timeout"`
+
+	// InstanceLaunchTimeout and ImageCreateTimeout override StateTimeout
+	// when waiting for the instance to be RUNNING and the image to be
+	// AVAILABLE respectively.
+	InstanceLaunchTimeout time.Duration `mapstructure:"instance_launch_timeout"`
+	ImageCreateTimeout    time.Duration `mapstructure:"image_create_timeout"`
+
+	// WaitForAgent waits, after the instance is RUNNING, for its communicator
+	// port to accept connections. The wait is bounded by StateTimeout.
+	WaitForAgent bool `mapstructure:"wait_for_agent"`
+
+	// APIMaxRetries is how many times a request to the OCI API is retried
+	// after failing with one of APIRetryableStatusCodes.
+	APIMaxRetries           *int  `mapstructure:"api_max_retries"`
+	APIRetryableStatusCodes []int `mapstructure:"api_retryable_status_codes"`
+
+	// HTTPTimeout bounds each request to the OCI API, including reading the
+	// response, so that a stuck connection fails the build rather than
+	// hanging it.
+	HTTPTimeout time.Duration `mapstructure:"http_timeout"`
+
+	// ServiceEndpointOverride replaces the public endpoint of the compute,
+	// block storage and virtual network APIs, e.g. with a private endpoint
+	// reachable from an air-gapped VCN. IdentityEndpointOverride does the
+	// same for the identity API, which OCI serves from a separate endpoint.
+	ServiceEndpointOverride  string `mapstructure:"service_endpoint_override"`
+	IdentityEndpointOverride string `mapstructure:"identity_endpoint_override"`
+
 	// Networking
 	SubnetID          string            `mapstructure:"subnet_ocid"`
+	NsgIDs            []string          `mapstructure:"nsg_ocids"`
+	HostnameLabel     string            `mapstructure:"hostname_label"`
 	CreateVnicDetails CreateVNICDetails `mapstructure:"create_vnic_details"`
 
+	// SubnetName is the display name of the subnet of VcnID to launch the
+	// instance in, looked up when the build starts if SubnetID isn't set.
+	SubnetName string `mapstructure:"subnet_name"`
+	VcnID      string `mapstructure:"vcn_ocid"`
+
+	// EphemeralPublicIP gives the instance's primary VNIC a public IP to
+	// provision over, which is deleted before the image is created.
+	EphemeralPublicIP bool `mapstructure:"ephemeral_public_ip"`
+
+	// CommVnicIndex and CommVnicName select the VNIC whose IP the
+	// communicator connects to, instead of the primary VNIC. VNICs are
+	// indexed in the order they were attached.
+	CommVnicIndex *int   `mapstructure:"comm_vnic_index"`
+	CommVnicName  string `mapstructure:"comm_vnic_name"`
+
 	// Tagging
-	Tags        map[string]string                 `mapstructure:"tags"`
-	DefinedTags map[string]map[string]interface{} `mapstructure:"defined_tags"`
+	// ImageTags and ImageDefinedTags are applied to the resulting image. Tags
+	// and DefinedTags are accepted as aliases for them.
+	ImageTags        map[string]string                 `mapstructure:"image_tags"`
+	ImageDefinedTags map[string]map[string]interface{} `mapstructure:"image_defined_tags"`
+	Tags             map[string]string                 `mapstructure:"tags"`
+	DefinedTags      map[string]map[string]interface{} `mapstructure:"defined_tags"`
+
+	// ImageTagsFromEnv adds freeform tags to the image whose values are read
+	// from the named environment variables, such as a CI build number.
+	ImageTagsFromEnv map[string]string `mapstructure:"image_tags_from_env"`
+
+	// TagBaseImage tags the image with the OCID of the base image it was
+	// built from, once a base_image_filter or import has been resolved.
+	TagBaseImage bool `mapstructure:"tag_base_image"`
+
+	// SkipTagValidation skips checking that the namespaces of the defined
+	// tags exist before launching the instance, which needs permission to
+	// read the tenancy's tag namespaces.
+	SkipTagValidation bool `mapstructure:"skip_tag_validation"`
+
+	// SkipAvailabilityDomainValidation skips checking that
+	// availability_domain is one of the region's before launching the
+	// instance, which needs permission to list them.
+	SkipAvailabilityDomainValidation bool `mapstructure:"skip_availability_domain_validation"`
 
 	ctx interpolate.Context
 }
@@ -135,14 +469,39 @@ func (c *Config) Prepare(raws ...interface{}) error {
 		errs = packersdk.MultiErrorAppend(errs, es...)
 	}
 
+	switch c.Comm.Type {
+	case "ssh", "winrm", "none":
+	default:
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+			"communicator %q is not supported, must be one of \"ssh\", \"winrm\" or \"none\"", c.Comm.Type))
+	}
+
 	var tenancyOCID string
 
-	if c.InstancePrincipals {
+	switch c.AuthType {
+	case "":
+		c.AuthType = authTypeAPIKey
+		if c.InstancePrincipals {
+			c.AuthType = authTypeInstancePrincipal
+		}
+	case authTypeAPIKey:
+		if c.InstancePrincipals {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+				"auth_type %q cannot be used when use_instance_principals is set to true", c.AuthType))
+		}
+	case authTypeInstancePrincipal:
+		c.InstancePrincipals = true
+	default:
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+			"auth_type must be one of %q or %q, got %q", authTypeAPIKey, authTypeInstancePrincipal, c.AuthType))
+	}
+
+	if c.AuthType == authTypeInstancePrincipal {
 		// We could go through all keys in one go and report that the below set
 		// of keys cannot coexist with use_instance_principals but decided to
 		// split them and report them seperately so that the user sees the specific
 		// key involved.
-		var message string = " cannot be present when use_instance_principals is set to true."
+		var message string = " cannot be present when using instance principals."
 		if c.AccessCfgFile != "" {
 			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("access_cfg_file"+message))
 		}
@@ -164,24 +523,38 @@ func (c *Config) Prepare(raws ...interface{}) error {
 		if c.KeyFile != "" {
 			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("key_file"+message))
 		}
+		if c.KeyContent != "" {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("key_content"+message))
+		}
 		if c.PassPhrase != "" {
 			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("pass_phrase"+message))
 		}
-		// This check is used to facilitate testing. During testing a Mock struct
-		// is assigned to c.configProvider otherwise testing fails because Instance
-		// Principals cannot be obtained.
-		if c.configProvider == nil {
-			// Even though the previous configuraion checks might fail we don't want
-			// to skip this step. It seems that the logic behind the checks in this
-			// file is to check everything even getting the configProvider.
-			c.configProvider, err = ociauth.InstancePrincipalConfigurationProvider()
+		// Even though the previous configuraion checks might fail we don't want
+		// to skip this step. It seems that the logic behind the checks in this
+		// file is to check everything even getting the configProvider.
+		c.configProvider, err = buildConfigProvider(c)
+		if err != nil {
+			return err
+		}
+		// The tenancy is only needed as the default compartment, so a
+		// failure to read it is reported with the other errors rather than
+		// aborting the validation.
+		if c.CompartmentID == "" {
+			tenancyOCID, err = c.configProvider.TenancyOCID()
 			if err != nil {
-				return err
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+					"'compartment_ocid' must be specified as the tenancy could not be determined from instance metadata: %s", err))
 			}
 		}
-		tenancyOCID, err = c.configProvider.TenancyOCID()
+
+		// The region comes from the instance metadata rather than the
+		// template.
+		region, err := c.configProvider.Region()
 		if err != nil {
-			return err
+			return fmt.Errorf("Unable to determine region from instance metadata: %s", err)
+		}
+		if c.Region == "" {
+			c.Region = region
 		}
 	} else {
 		// Determine where the SDK config is located
@@ -192,50 +565,55 @@ func (c *Config) Prepare(raws ...interface{}) error {
 			}
 		}
 
-		if c.AccessCfgFileAccount == "" {
-			c.AccessCfgFileAccount = "DEFAULT"
-		}
-
-		var keyContent []byte
-		if c.KeyFile != "" {
-			path, err := pathing.ExpandUser(c.KeyFile)
+		// Resolve the paths up front so that they don't depend on how the
+		// SDK and the checks below treat ~ and relative paths, or on the
+		// directory Packer is run from.
+		if c.AccessCfgFile != "" {
+			path, err := expandPath(c.AccessCfgFile, c.templateDir())
 			if err != nil {
-				return err
+				errs = packersdk.MultiErrorAppend(
+					errs, fmt.Errorf("Unable to resolve access_cfg_file %s: %s", c.AccessCfgFile, err))
+			} else {
+				c.AccessCfgFile = path
 			}
-
-			// Read API signing key
-			keyContent, err = ioutil.ReadFile(path)
+		}
+		if c.KeyFile != "" {
+			path, err := expandPath(c.KeyFile, c.templateDir())
 			if err != nil {
-				return err
+				errs = packersdk.MultiErrorAppend(
+					errs, fmt.Errorf("Unable to resolve key_file %s: %s", c.KeyFile, err))
+			} else {
+				c.KeyFile = path
 			}
 		}
 
-		fileProvider, _ := ocicommon.ConfigurationProviderFromFileWithProfile(c.AccessCfgFile, c.AccessCfgFileAccount, c.PassPhrase)
-		if c.Region == "" {
-			var region string
-			if fileProvider != nil {
-				region, _ = fileProvider.Region()
-			}
-			if region == "" {
-				c.Region = "us-phoenix-1"
-			}
+		if c.AccessCfgFileAccount == "" {
+			c.AccessCfgFileAccount = "DEFAULT"
 		}
 
-		providers := []ocicommon.ConfigurationProvider{
-			NewRawConfigurationProvider(c.TenancyID, c.UserID, c.Region, c.Fingerprint, string(keyContent), &c.PassPhrase),
+		if err := validateProfile(c.AccessCfgFile, c.AccessCfgFileAccount); err != nil {
+			errs = packersdk.MultiErrorAppend(errs, err)
 		}
 
-		if fileProvider != nil {
-			providers = append(providers, fileProvider)
+		if c.KeyFile != "" && c.KeyContent != "" {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("Only one of 'key_file' or 'key_content' can be specified"))
 		}
 
-		// Load API access configuration from SDK
-		configProvider, err := ocicommon.ComposingConfigurationProvider(providers)
+		configProvider, err := buildConfigProvider(c)
 		if err != nil {
 			return err
 		}
+		// buildConfigProvider has read the key already.
+		keyContent, _ := c.templateSigningKey()
+
+		// Profiles created by `oci session authenticate` have a
+		// security_token_file instead of a user and fingerprint. The SDK
+		// signs requests with the token as the key ID.
+		keyID, _ := configProvider.KeyID()
+		securityTokenAuth := strings.HasPrefix(keyID, securityTokenKeyIDPrefix)
 
-		if userOCID, _ := configProvider.UserOCID(); userOCID == "" {
+		if userOCID, _ := configProvider.UserOCID(); userOCID == "" && !securityTokenAuth {
 			errs = packersdk.MultiErrorAppend(
 				errs, errors.New("'user_ocid' must be specified"))
 		}
@@ -246,14 +624,22 @@ func (c *Config) Prepare(raws ...interface{}) error {
 				errs, errors.New("'tenancy_ocid' must be specified"))
 		}
 
-		if fingerprint, _ := configProvider.KeyFingerprint(); fingerprint == "" {
+		if fingerprint, _ := configProvider.KeyFingerprint(); fingerprint == "" && !securityTokenAuth {
 			errs = packersdk.MultiErrorAppend(
 				errs, errors.New("'fingerprint' must be specified"))
 		}
 
+		if region, _ := configProvider.Region(); region != "" {
+			if err := validateRegion(region); err != nil {
+				errs = packersdk.MultiErrorAppend(errs, err)
+			}
+		}
+
 		if _, err := configProvider.PrivateRSAKey(); err != nil {
 			errs = packersdk.MultiErrorAppend(
-				errs, fmt.Errorf("'key_file' must be correctly specified. %w", err))
+				errs, c.privateKeyError(keyContent))
+		} else if err := c.validatePassPhrase(keyContent); err != nil {
+			errs = packersdk.MultiErrorAppend(errs, err)
 		}
 
 		c.configProvider = configProvider
@@ -264,7 +650,90 @@ func (c *Config) Prepare(raws ...interface{}) error {
 			errs, errors.New("'availability_domain' must be specified"))
 	}
 
-	if c.CompartmentID == "" && tenancyOCID != "" {
+	if c.FaultDomain != nil && strings.TrimSpace(*c.FaultDomain) == "" {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'fault_domain' must not be empty"))
+	}
+
+	// Malformed OCIDs are otherwise only rejected by the API once the build
+	// is underway.
+	for _, id := range []struct{ key, value, resourceType string }{
+		{"user_ocid", c.UserID, "user"},
+		{"tenancy_ocid", c.TenancyID, "tenancy"},
+		{"dedicated_vm_host_ocid", c.DedicatedVmHostID, "dedicatedvmhost"},
+		{"capacity_reservation_ocid", c.CapacityReservationID, "capacityreservation"},
+		{"base_image_ocid", c.BaseImageID, "image"},
+		{"source_boot_volume_ocid", c.SourceBootVolumeID, "bootvolume"},
+		{"marketplace_listing_ocid", c.MarketplaceListingID, "appcataloglisting"},
+		{"kms_key_ocid", c.KmsKeyID, "key"},
+		{"image_capability_schema_ocid", c.ImageCapabilitySchemaID, "computeimagecapabilityschema"},
+		{"subnet_ocid", c.SubnetID, "subnet"},
+		{"vcn_ocid", c.VcnID, "vcn"},
+		{"use_instance_ocid", c.UseInstanceID, "instance"},
+	} {
+		if id.value == "" {
+			continue
+		}
+		if err := validateOCID(id.value, id.resourceType); err != nil {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("'%s' %s", id.key, err))
+		}
+	}
+
+	// Images are regional, and launching from one in another region fails
+	// with a 404 that doesn't say why. Regions unknown to the SDK can't be
+	// told apart from their short codes, so aren't compared.
+	if region := ocidRegion(c.BaseImageID); region != "" && validateRegion(region) == nil && validateRegion(c.Region) == nil &&
+		ocicommon.StringToRegion(region) != ocicommon.StringToRegion(c.Region) {
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+			"'base_image_ocid' is an image in region %s, but the build runs in region %s: "+
+				"use the OCID of the image in %s or set 'region'", region, c.Region, c.Region))
+	}
+
+	for _, id := range c.NsgIDs {
+		if err := validateOCID(id, "networksecuritygroup"); err != nil {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("'nsg_ocids' %s", err))
+		}
+	}
+
+	// The root compartment's OCID is the tenancy's.
+	for key, id := range map[string]string{
+		"compartment_ocid":             c.CompartmentID,
+		"image_compartment_ocid":       c.ImageCompartmentID,
+		"final_image_compartment_ocid": c.FinalImageCompartmentID,
+		"network_compartment_ocid":     c.NetworkCompartmentID,
+		"identity_compartment_ocid":    c.IdentityCompartmentID,
+	} {
+		if id != "" && validateOCID(id, "compartment") != nil && validateOCID(id, "tenancy") != nil {
+			errs = packersdk.MultiErrorAppend(
+				errs, fmt.Errorf("'%s' must be a compartment or tenancy OCID, found %q", key, id))
+		}
+	}
+
+	if c.IdentityCompartmentID == "" {
+		c.IdentityCompartmentID = tenancyOCID
+	}
+
+	if c.CompartmentName != "" {
+		if c.CompartmentID != "" {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("Only one of 'compartment_ocid' or 'compartment_name' can be specified"))
+		} else if errs == nil && c.IdentityCompartmentID != "" {
+			// Only call the API once the credentials are known to be usable.
+			client, err := newIdentityClient(c.configProvider)
+			if err == nil {
+				if c.IdentityEndpointOverride != "" {
+					client.Host = c.IdentityEndpointOverride
+				}
+				c.CompartmentID, err = findCompartmentID(context.TODO(), client, c.IdentityCompartmentID, c.CompartmentName)
+			}
+			if err != nil {
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+					"Unable to resolve 'compartment_name' %q: %s", c.CompartmentName, err))
+			}
+		}
+	}
+
+	if c.CompartmentID == "" && tenancyOCID != "" && c.CompartmentName == "" {
 		c.CompartmentID = tenancyOCID
 	}
 
@@ -272,14 +741,44 @@ func (c *Config) Prepare(raws ...interface{}) error {
 		c.ImageCompartmentID = c.CompartmentID
 	}
 
-	if c.Shape == "" {
+	if c.NetworkCompartmentID == "" {
+		c.NetworkCompartmentID = c.CompartmentID
+	}
+
+	if c.Shape == "" && c.UseInstanceID == "" {
 		errs = packersdk.MultiErrorAppend(
 			errs, errors.New("'shape' must be specified"))
 	}
 
-	if (c.SubnetID == "") && (c.CreateVnicDetails.SubnetId == nil) {
+	// Flexible shapes have no default number of OCPUs so the launch fails
+	// with an opaque API error if none are requested.
+	if c.ShapeOCPUs < 0 {
 		errs = packersdk.MultiErrorAppend(
-			errs, errors.New("'subnet_ocid' must be specified"))
+			errs, errors.New("'shape_ocpus' must be a positive number"))
+	} else if c.ShapeOCPUs == 0 && strings.HasSuffix(c.Shape, ".Flex") && !isBareMetalShape(c.Shape) {
+		errs = packersdk.MultiErrorAppend(
+			errs, fmt.Errorf("'shape_ocpus' must be specified for flexible shape %q", c.Shape))
+	}
+	if c.ShapeMemoryInGBs < 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'shape_memory_in_gbs' must be a positive number"))
+	} else if c.ShapeMemoryInGBs != 0 && c.Shape != "" && !strings.HasSuffix(c.Shape, ".Flex") {
+		errs = packersdk.MultiErrorAppend(
+			errs, fmt.Errorf("'shape_memory_in_gbs' can only be specified for a flexible shape, found %q", c.Shape))
+	}
+
+	if c.SubnetName != "" {
+		if c.SubnetID != "" || c.CreateVnicDetails.SubnetId != nil {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("Only one of 'subnet_ocid' or 'subnet_name' can be specified"))
+		}
+		if c.VcnID == "" {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("'vcn_ocid' must be specified with 'subnet_name'"))
+		}
+	} else if (c.SubnetID == "") && (c.CreateVnicDetails.SubnetId == nil) && (c.UseInstanceID == "") {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'subnet_ocid' or 'subnet_name' must be specified"))
 	}
 
 	if c.CreateVnicDetails.SubnetId == nil {
@@ -289,9 +788,235 @@ func (c *Config) Prepare(raws ...interface{}) error {
 			errs, errors.New("'create_vnic_details[subnet]' must match 'subnet_ocid' if both are specified"))
 	}
 
-	if (c.BaseImageID == "") && (c.BaseImageFilter == ListImagesRequest{}) {
+	if c.IPVersion == "" {
+		c.IPVersion = ipVersionIPv4
+	} else if err := validateOneOf("ip_version", c.IPVersion, []string{ipVersionIPv4, ipVersionIPv6}); err != nil {
+		errs = packersdk.MultiErrorAppend(errs, err)
+	}
+
+	if c.EphemeralPublicIP {
+		if c.IPVersion == ipVersionIPv6 {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("'ip_version' cannot be ipv6 with 'ephemeral_public_ip'"))
+		}
+		if c.UsePrivateIP {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("'use_private_ip' cannot be specified with 'ephemeral_public_ip'"))
+		}
+		if c.CreateVnicDetails.AssignPublicIp != nil && !*c.CreateVnicDetails.AssignPublicIp {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("'create_vnic_details[assign_public_ip]' cannot be false with 'ephemeral_public_ip'"))
+		}
+	}
+
+	// Only ask for a public IP when it's going to be used to connect, as
+	// subnets that prohibit public IPs otherwise reject the launch.
+	if c.CreateVnicDetails.AssignPublicIp == nil {
+		assignPublicIp := !c.UsePrivateIP && c.IPVersion != ipVersionIPv6
+		c.CreateVnicDetails.AssignPublicIp = &assignPublicIp
+	}
+
+	if c.CreateVnicDetails.NsgIds == nil {
+		c.CreateVnicDetails.NsgIds = c.NsgIDs
+	} else if c.NsgIDs != nil && !reflect.DeepEqual(c.CreateVnicDetails.NsgIds, c.NsgIDs) {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'create_vnic_details[nsg_ids]' must match 'nsg_ocids' if both are specified"))
+	}
+
+	if c.CreateVnicDetails.HostnameLabel == nil {
+		if c.HostnameLabel != "" {
+			c.CreateVnicDetails.HostnameLabel = &c.HostnameLabel
+		}
+	} else if c.HostnameLabel != "" && *c.CreateVnicDetails.HostnameLabel != c.HostnameLabel {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'create_vnic_details[hostname_label]' must match 'hostname_label' if both are specified"))
+	}
+
+	if label := c.CreateVnicDetails.HostnameLabel; label != nil && !hostnameLabelRegexp.MatchString(*label) {
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+			"'hostname_label' must be at most 63 lowercase letters, digits and hyphens, not starting or ending with a hyphen, found %q", *label))
+	}
+
+	if c.CommVnicIndex != nil && c.CommVnicName != "" {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("Only one of comm_vnic_index or comm_vnic_name can be specified."))
+	} else if c.CommVnicIndex != nil && *c.CommVnicIndex < 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'comm_vnic_index' must not be negative"))
+	}
+
+	if c.LaunchNetworkType != "" {
+		var allowed []string
+		for _, v := range core.GetLaunchOptionsNetworkTypeEnumValues() {
+			allowed = append(allowed, string(v))
+		}
+		if err := validateOneOf("launch_network_type", c.LaunchNetworkType, allowed); err != nil {
+			errs = packersdk.MultiErrorAppend(errs, err)
+		}
+	}
+
+	if c.LaunchBootVolumeType != "" {
+		var allowed []string
+		for _, v := range core.GetLaunchOptionsBootVolumeTypeEnumValues() {
+			allowed = append(allowed, string(v))
+		}
+		if err := validateOneOf("launch_boot_volume_type", c.LaunchBootVolumeType, allowed); err != nil {
+			errs = packersdk.MultiErrorAppend(errs, err)
+		}
+	}
+
+	if c.LaunchFirmware != "" {
+		var allowed []string
+		for _, v := range core.GetLaunchOptionsFirmwareEnumValues() {
+			allowed = append(allowed, string(v))
+		}
+		if err := validateOneOf("launch_firmware", c.LaunchFirmware, allowed); err != nil {
+			errs = packersdk.MultiErrorAppend(errs, err)
+		}
+	}
+
+	if c.LaunchMode != "" {
+		var allowed []string
+		for _, v := range core.GetCreateImageDetailsLaunchModeEnumValues() {
+			allowed = append(allowed, string(v))
+		}
+		if err := validateOneOf("image_launch_mode", c.LaunchMode, allowed); err != nil {
+			errs = packersdk.MultiErrorAppend(errs, err)
+		}
+	}
+
+	if (c.ImageOperatingSystem == "") != (c.ImageOperatingSystemVersion == "") {
+		errs = packersdk.MultiErrorAppend(errs, errors.New(
+			"'image_operating_system' and 'image_operating_system_version' must be specified together"))
+	}
+
+	for i := range c.BlockVolumes {
+		volume := &c.BlockVolumes[i]
+		if volume.SizeInGBs < 50 || volume.SizeInGBs > 32768 {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+				"'block_volumes[%d].size_in_gbs' must be between 50 and 32768, found %d", i, volume.SizeInGBs))
+		}
+		if volume.AttachmentType == "" {
+			volume.AttachmentType = "paravirtualized"
+		} else if err := validateOneOf(fmt.Sprintf("block_volumes[%d].attachment_type", i), volume.AttachmentType,
+			[]string{"paravirtualized", "iscsi"}); err != nil {
+			errs = packersdk.MultiErrorAppend(errs, err)
+		}
+	}
+
+	for i, vnic := range c.SecondaryVnics {
+		if vnic.SubnetID == "" {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+				"'secondary_vnics[%d].subnet_ocid' must be specified", i))
+		} else if err := validateOCID(vnic.SubnetID, "subnet"); err != nil {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("'secondary_vnics[%d].subnet_ocid' %s", i, err))
+		}
+		for _, id := range vnic.NsgIDs {
+			if err := validateOCID(id, "networksecuritygroup"); err != nil {
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("'secondary_vnics[%d].nsg_ocids' %s", i, err))
+			}
+		}
+	}
+
+	if c.BaseImageImportBucket != "" || c.BaseImageImportNamespace != "" || c.BaseImageImportName != "" {
+		if c.BaseImageImportBucket == "" {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("'base_image_import_bucket' must be specified when importing the base image"))
+		}
+		if c.BaseImageImportNamespace == "" {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("'base_image_import_namespace' must be specified when importing the base image"))
+		}
+		if c.BaseImageImportName == "" {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("'base_image_import_name' must be specified when importing the base image"))
+		}
+		if (c.BaseImageID != "") || (c.BaseImageFilter != ListImagesRequest{}) || (c.SourceBootVolumeID != "") {
+			errs = packersdk.MultiErrorAppend(errs, errors.New(
+				"'base_image_import_bucket' cannot be specified with 'base_image_ocid', 'base_image_filter' or 'source_boot_volume_ocid'"))
+		}
+	} else if c.BaseImageImportFormat != "" {
 		errs = packersdk.MultiErrorAppend(
-			errs, errors.New("'base_image_ocid' or 'base_image_filter' must be specified"))
+			errs, errors.New("'base_image_import_format' can only be specified with 'base_image_import_bucket'"))
+	}
+
+	if c.BaseImageImportFormat != "" {
+		var allowed []string
+		for _, v := range core.GetImageSourceDetailsSourceImageTypeEnumValues() {
+			allowed = append(allowed, string(v))
+		}
+		if err := validateOneOf("base_image_import_format", c.BaseImageImportFormat, allowed); err != nil {
+			errs = packersdk.MultiErrorAppend(errs, err)
+		}
+	}
+
+	if c.SourceBootVolumeID != "" {
+		if (c.BaseImageID != "") || (c.BaseImageFilter != ListImagesRequest{}) {
+			errs = packersdk.MultiErrorAppend(errs, errors.New(
+				"'source_boot_volume_ocid' cannot be specified with 'base_image_ocid' or 'base_image_filter'"))
+		}
+		if c.BootVolumeSizeInGBs != 0 {
+			errs = packersdk.MultiErrorAppend(errs, errors.New(
+				"'disk_size' cannot be specified with 'source_boot_volume_ocid'"))
+		}
+		if c.KmsKeyID != "" {
+			errs = packersdk.MultiErrorAppend(errs, errors.New(
+				"'kms_key_ocid' cannot be specified with 'source_boot_volume_ocid'"))
+		}
+		if c.BootVolumeVpusPerGB != 0 {
+			errs = packersdk.MultiErrorAppend(errs, errors.New(
+				"'boot_volume_vpus_per_gb' cannot be specified with 'source_boot_volume_ocid'"))
+		}
+	} else if (c.BaseImageID == "") && (c.BaseImageFilter == ListImagesRequest{}) && (c.BaseImageImportBucket == "") && (c.MarketplaceListingID == "") && (c.UseInstanceID == "") {
+		errs = packersdk.MultiErrorAppend(errs, errors.New(
+			"'base_image_ocid', 'base_image_filter', 'base_image_import_bucket', 'marketplace_listing_ocid' or 'source_boot_volume_ocid' must be specified"))
+	}
+
+	if c.MarketplaceListingID != "" {
+		if (c.BaseImageID != "") || (c.BaseImageFilter != ListImagesRequest{}) || (c.BaseImageImportBucket != "") || (c.SourceBootVolumeID != "") {
+			errs = packersdk.MultiErrorAppend(errs, errors.New(
+				"'marketplace_listing_ocid' cannot be specified with 'base_image_ocid', 'base_image_filter', 'base_image_import_bucket' or 'source_boot_volume_ocid'"))
+		}
+	} else if c.MarketplacePackageVersion != "" || c.MarketplaceAcceptAgreement {
+		errs = packersdk.MultiErrorAppend(errs, errors.New(
+			"'marketplace_package_version' and 'marketplace_accept_agreement' can only be specified with 'marketplace_listing_ocid'"))
+	}
+
+	if c.UseInstanceID != "" {
+		for key, set := range map[string]bool{
+			"shape":                          c.Shape != "",
+			"base_image_ocid":                c.BaseImageID != "",
+			"base_image_filter":              c.BaseImageFilter != ListImagesRequest{},
+			"base_image_import_bucket":       c.BaseImageImportBucket != "",
+			"source_boot_volume_ocid":        c.SourceBootVolumeID != "",
+			"marketplace_listing_ocid":       c.MarketplaceListingID != "",
+			"subnet_ocid":                    c.CreateVnicDetails.SubnetId != nil && *c.CreateVnicDetails.SubnetId != "",
+			"subnet_name":                    c.SubnetName != "",
+			"dedicated_vm_host_ocid":         c.DedicatedVmHostID != "",
+			"kms_key_ocid":                   c.KmsKeyID != "",
+			"boot_volume_vpus_per_gb":        c.BootVolumeVpusPerGB != 0,
+			"capacity_reservation_ocid":      c.CapacityReservationID != "",
+			"preemptible":                    c.Preemptible,
+			"disable_legacy_imds_endpoints":  c.DisableLegacyImdsEndpoints,
+			"secure_boot":                    c.SecureBoot,
+			"measured_boot":                  c.MeasuredBoot,
+			"trusted_platform_module":        c.TrustedPlatformModule,
+			"ephemeral_public_ip":            c.EphemeralPublicIP,
+			"stop_before_image":              c.StopBeforeImage,
+			"agent_disabled_plugins":         len(c.AgentDisabledPlugins) > 0,
+			"agent_are_all_plugins_disabled": c.AgentAreAllPluginsDisabled,
+		} {
+			if set {
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("'%s' cannot be specified with 'use_instance_ocid'", key))
+			}
+		}
+
+		// The temporary key pair Packer generates is only given to the
+		// instances it launches.
+		if c.Comm.Type == "ssh" && c.Comm.SSHPrivateKeyFile == "" && !c.Comm.SSHAgentAuth && c.Comm.SSHPassword == "" {
+			errs = packersdk.MultiErrorAppend(errs, errors.New(
+				"'ssh_private_key_file', 'ssh_agent_auth' or 'ssh_password' must be specified with 'use_instance_ocid'"))
+		}
 	}
 
 	if c.BaseImageFilter.CompartmentId == nil {
@@ -302,9 +1027,55 @@ func (c *Config) Prepare(raws ...interface{}) error {
 		c.BaseImageFilter.Shape = &c.Shape
 	}
 
+	if c.Tags != nil && c.ImageTags != nil {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("Only one of tags or image_tags can be specified."))
+	} else if c.ImageTags == nil {
+		c.ImageTags = c.Tags
+	}
+
+	if c.DefinedTags != nil && c.ImageDefinedTags != nil {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("Only one of defined_tags or image_defined_tags can be specified."))
+	} else if c.ImageDefinedTags == nil {
+		c.ImageDefinedTags = c.DefinedTags
+	}
+
+	// Templates can only use the env function in their variables, so the
+	// environment is read here instead.
+	for key, name := range c.ImageTagsFromEnv {
+		if _, ok := c.ImageTags[key]; ok {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+				"'image_tags_from_env' tag %q is also set by 'image_tags'", key))
+			continue
+		}
+		value := os.Getenv(name)
+		if value == "" {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+				"'image_tags_from_env' tag %q requires environment variable %s, which is empty", key, name))
+			continue
+		}
+		if c.ImageTags == nil {
+			c.ImageTags = map[string]string{}
+		}
+		c.ImageTags[key] = value
+	}
+
+	// Empty tag maps are omitted from the request entirely.
+	if len(c.ImageTags) == 0 {
+		c.ImageTags = nil
+	}
+	if len(c.ImageDefinedTags) == 0 {
+		c.ImageDefinedTags = nil
+	}
+
+	if len(c.InstanceTags) == 0 {
+		c.InstanceTags = nil
+	}
+
 	// Validate tag lengths. TODO (hlowndes) maximum number of tags allowed.
-	if c.Tags != nil {
-		for k, v := range c.Tags {
+	if c.ImageTags != nil {
+		for k, v := range c.ImageTags {
 			k = strings.TrimSpace(k)
 			v = strings.TrimSpace(v)
 			if len(k) > 100 {
@@ -326,32 +1097,256 @@ func (c *Config) Prepare(raws ...interface{}) error {
 		}
 	}
 
-	if c.ImageName == "" {
+	if c.ImageName != "" && (c.ImageNamePrefix != "" || c.ImageNameTimestampFormat != "") {
+		errs = packersdk.MultiErrorAppend(errs, errors.New(
+			"'image_name_prefix' and 'image_name_timestamp_format' cannot be specified with 'image_name'"))
+	} else if c.ImageName == "" {
+		prefix := c.ImageNamePrefix
+		if prefix == "" {
+			prefix = "packer"
+		}
+
+		if c.ImageNameTimestampFormat != "" {
+			if err := validateTimestampFormat(c.ImageNameTimestampFormat); err != nil {
+				errs = packersdk.MultiErrorAppend(errs, err)
+			}
+			c.ImageName = prefix + "-" + time.Now().UTC().Format(c.ImageNameTimestampFormat)
+		} else {
+			name, err := interpolate.Render(prefix+"-{{timestamp}}", nil)
+			if err != nil {
+				errs = packersdk.MultiErrorAppend(errs,
+					fmt.Errorf("unable to parse image name: %s", err))
+			} else {
+				c.ImageName = name
+			}
+		}
+
+		if !defaultImageNameRegexp.MatchString(c.ImageName) {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+				"'image_name_prefix' and 'image_name_timestamp_format' must only produce letters, digits, "+
+					"spaces and '_', '-', '.', ':' or '+', got image name %q", c.ImageName))
+		}
+	}
+
+	// Display names are limited to 255 characters, which the API only
+	// enforces once the instance has been provisioned.
+	if len(c.ImageName) > 255 {
+		errs = packersdk.MultiErrorAppend(
+			errs, fmt.Errorf("'image_name' must be at most 255 characters, found %d", len(c.ImageName)))
+	}
+
+	if c.InstanceName == nil {
 		name, err := interpolate.Render("packer-{{timestamp}}", nil)
 		if err != nil {
 			errs = packersdk.MultiErrorAppend(errs,
-				fmt.Errorf("unable to parse image name: %s", err))
+				fmt.Errorf("unable to parse instance name: %s", err))
 		} else {
-			c.ImageName = name
+			c.InstanceName = &name
 		}
 	}
 
-	// Optional UserData config
-	if c.UserData != "" && c.UserDataFile != "" {
-		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("Only one of user_data or user_data_file can be specified."))
-	} else if c.UserDataFile != "" {
-		if _, err := os.Stat(c.UserDataFile); err != nil {
-			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("user_data_file not found: %s", c.UserDataFile))
+	if c.InstanceName != nil {
+		if len(*c.InstanceName) == 0 {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("'instance_name' must not be empty"))
+		} else if len(*c.InstanceName) > 255 {
+			errs = packersdk.MultiErrorAppend(
+				errs, fmt.Errorf("'instance_name' must be at most 255 characters, found %d", len(*c.InstanceName)))
 		}
 	}
-	// read UserDataFile into string.
-	if c.UserDataFile != "" {
-		fiData, err := ioutil.ReadFile(c.UserDataFile)
-		if err != nil {
+
+	if c.ImageExportBucket != "" || c.ImageExportNamespace != "" || c.ImageExportName != "" || c.ImageExportFormat != "" {
+		if c.ImageExportBucket == "" {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("'image_export_bucket' must be specified when exporting the image"))
+		}
+		if c.ImageExportNamespace == "" {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("'image_export_namespace' must be specified when exporting the image"))
+		}
+		if c.ImageExportName == "" {
+			c.ImageExportName = c.ImageName
+		}
+		if c.ImageExportFormat != "" {
+			if err := validateOneOf("image_export_format", c.ImageExportFormat, []string{"oci", "qcow2", "vmdk", "vhd"}); err != nil {
+				errs = packersdk.MultiErrorAppend(errs, err)
+			}
+		}
+	}
+
+	for i, plugin := range c.AgentDisabledPlugins {
+		if strings.TrimSpace(plugin) == "" {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("'agent_disabled_plugins[%d]' must not be empty", i))
+		}
+	}
+
+	if c.Preemptible {
+		for key, value := range map[string]string{
+			"capacity_reservation_ocid": c.CapacityReservationID,
+			"dedicated_vm_host_ocid":    c.DedicatedVmHostID,
+			"source_boot_volume_ocid":   c.SourceBootVolumeID,
+		} {
+			if value != "" {
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("'%s' cannot be specified with 'preemptible'", key))
+			}
+		}
+	}
+
+	// OCI rejects the launch of a shielded instance on other shapes with a
+	// message that doesn't mention the shape.
+	if (c.SecureBoot || c.MeasuredBoot || c.TrustedPlatformModule) && c.UseInstanceID == "" {
+		if shieldedPlatformType(c.Shape) == "" {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+				"'shape' %q does not support shielded instances, which 'secure_boot', 'measured_boot' and 'trusted_platform_module' launch",
+				c.Shape))
+		}
+		if c.MeasuredBoot && !c.TrustedPlatformModule {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("'measured_boot' requires 'trusted_platform_module'"))
+		}
+		if c.SecureBoot && c.LaunchFirmware != "" && c.LaunchFirmware != string(core.LaunchOptionsFirmwareUefi64) {
+			errs = packersdk.MultiErrorAppend(
+				errs, fmt.Errorf("'secure_boot' requires 'launch_firmware' to be %s", core.LaunchOptionsFirmwareUefi64))
+		}
+	}
+
+	if c.SkipCreateImage && c.ImageExportBucket != "" {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'image_export_bucket' cannot be specified with 'skip_create_image'"))
+	}
+	if c.SkipCreateImage && c.FinalImageCompartmentID != "" {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'final_image_compartment_ocid' cannot be specified with 'skip_create_image'"))
+	}
+
+	statePollIntervalKey := "state_poll_interval"
+	if c.StatePollInitial != 0 {
+		statePollIntervalKey = "state_poll_initial"
+		if c.StatePollInterval != 0 {
+			errs = packersdk.MultiErrorAppend(
+				errs, errors.New("Only one of state_poll_initial or state_poll_interval can be specified."))
+		}
+		c.StatePollInterval = c.StatePollInitial
+	}
+	if c.StatePollInterval == 0 {
+		c.StatePollInterval = 5 * time.Second
+	} else if c.StatePollInterval < 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, fmt.Errorf("'%s' must be a positive duration", statePollIntervalKey))
+	}
+
+	if c.APIMaxRetries == nil {
+		apiMaxRetries := defaultAPIMaxRetries
+		c.APIMaxRetries = &apiMaxRetries
+	} else if *c.APIMaxRetries < 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'api_max_retries' must not be negative"))
+	}
+
+	if c.HTTPTimeout == 0 {
+		c.HTTPTimeout = defaultHTTPTimeout
+	} else if c.HTTPTimeout < 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'http_timeout' must be a positive duration"))
+	}
+
+	for key, endpoint := range map[string]string{
+		"service_endpoint_override":  c.ServiceEndpointOverride,
+		"identity_endpoint_override": c.IdentityEndpointOverride,
+	} {
+		if endpoint == "" {
+			continue
+		}
+		if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+				"'%s' must be an http or https URL, got %q", key, endpoint))
+		}
+	}
+
+	if c.APIRetryableStatusCodes == nil {
+		c.APIRetryableStatusCodes = defaultAPIRetryableStatusCodes
+	}
+	for _, code := range c.APIRetryableStatusCodes {
+		if code < 400 || code > 599 {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+				"'api_retryable_status_codes' must only contain HTTP error status codes, found %d", code))
+		}
+	}
+
+	if c.StatePollMultiplier == 0 {
+		c.StatePollMultiplier = 1
+	} else if c.StatePollMultiplier < 1 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'state_poll_multiplier' must be at least 1"))
+	}
+
+	if c.StatePollMax < 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'state_poll_max' must be a positive duration"))
+	} else if c.StatePollMax > 0 && c.StatePollMax < c.StatePollInterval {
+		errs = packersdk.MultiErrorAppend(
+			errs, fmt.Errorf("'state_poll_max' must not be less than '%s'", statePollIntervalKey))
+	}
+
+	if c.StateTimeout < 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'state_timeout' must be a positive duration"))
+	}
+
+	if c.InstanceLaunchTimeout == 0 {
+		c.InstanceLaunchTimeout = c.StateTimeout
+		if isBareMetalShape(c.Shape) && c.StateTimeout > 0 && c.StateTimeout < bareMetalInstanceLaunchTimeout {
+			c.InstanceLaunchTimeout = bareMetalInstanceLaunchTimeout
+		}
+	} else if c.InstanceLaunchTimeout < 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'instance_launch_timeout' must be a positive duration"))
+	}
+
+	if c.ImageCreateTimeout == 0 {
+		c.ImageCreateTimeout = c.StateTimeout
+	} else if c.ImageCreateTimeout < 0 {
+		errs = packersdk.MultiErrorAppend(
+			errs, errors.New("'image_create_timeout' must be a positive duration"))
+	}
+
+	if _, ok := c.Metadata[sshAuthorizedKeysMetadataKey]; ok {
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+			"metadata[%s] is reserved for the communicator's SSH key and cannot be set", sshAuthorizedKeysMetadataKey))
+	}
+
+	if _, err := json.Marshal(c.ExtendedMetadata); err != nil {
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("extended_metadata must be serializable to JSON: %s", err))
+	}
+
+	// Optional UserData config
+	if c.UserData != "" && c.UserDataFile != "" {
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("Only one of user_data or user_data_file can be specified."))
+	} else if c.UserDataFile != "" {
+		if _, err := os.Stat(c.UserDataFile); err != nil {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("user_data_file not found: %s", c.UserDataFile))
+		} else if fiData, err := ioutil.ReadFile(c.UserDataFile); err != nil {
+			// read UserDataFile into string.
 			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("Problem reading user_data_file: %s", err))
+		} else {
+			c.UserData = string(fiData)
+		}
+	}
+	if len(c.Nameservers) > 0 || len(c.SearchDomains) > 0 {
+		for _, ns := range c.Nameservers {
+			if net.ParseIP(ns) == nil {
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+					"'nameservers' must only contain IP addresses, found %q", ns))
+			}
+		}
+		if c.UserData != "" || c.UserDataFile != "" {
+			errs = packersdk.MultiErrorAppend(errs, errors.New(
+				"'nameservers' and 'search_domains' cannot be specified with user_data or user_data_file"))
+		} else {
+			c.UserData = resolvConfUserData(c.Nameservers, c.SearchDomains)
 		}
-		c.UserData = string(fiData)
 	}
+
 	// Test if UserData is encoded already, and if not, encode it
 	if c.UserData != "" {
 		if _, err := base64.StdEncoding.DecodeString(c.UserData); err != nil {
@@ -360,15 +1355,19 @@ func (c *Config) Prepare(raws ...interface{}) error {
 		}
 	}
 
-	// Set default boot volume size to 50 if not set
-	// Check if size set is allowed by OCI
-	if c.BootVolumeSizeInGBs == 0 {
-		c.BootVolumeSizeInGBs = 50
-	} else if c.BootVolumeSizeInGBs < 50 || c.BootVolumeSizeInGBs > 16384 {
+	// Leave the boot volume size unset so the image's own default is used,
+	// otherwise check if size set is allowed by OCI
+	if c.BootVolumeSizeInGBs != 0 && (c.BootVolumeSizeInGBs < 50 || c.BootVolumeSizeInGBs > 16384) {
 		errs = packersdk.MultiErrorAppend(
 			errs, errors.New("'disk_size' must be between 50 and 16384 GBs"))
 	}
 
+	// Block volume performance comes in tiers of 10 VPUs per GB.
+	if c.BootVolumeVpusPerGB != 0 && (c.BootVolumeVpusPerGB < 10 || c.BootVolumeVpusPerGB > 120 || c.BootVolumeVpusPerGB%10 != 0) {
+		errs = packersdk.MultiErrorAppend(
+			errs, fmt.Errorf("'boot_volume_vpus_per_gb' must be a multiple of 10 from 10 to 120, found %d", c.BootVolumeVpusPerGB))
+	}
+
 	if errs != nil && len(errs.Errors) > 0 {
 		return errs
 	}
@@ -376,6 +1375,599 @@ func (c *Config) Prepare(raws ...interface{}) error {
 	return nil
 }
 
+// pollBackoff returns the polling backoff used when waiting on the state of
+// a resource.
+func (c *Config) pollBackoff() pollBackoff {
+	return pollBackoff{
+		Initial:    c.StatePollInterval,
+		Max:        c.StatePollMax,
+		Multiplier: c.StatePollMultiplier,
+	}
+}
+
+// warnings returns the problems with a prepared config that may be
+// intentional, so don't fail the build.
+func (c *Config) warnings() []string {
+	var warnings []string
+	// A private IP is only reachable from within the VCN or one peered with
+	// it, which Packer can't check.
+	if c.UsePrivateIP && c.Comm.Type == "ssh" && c.Comm.SSHBastionHost == "" && c.Comm.SSHProxyHost == "" {
+		warnings = append(warnings, "'use_private_ip' is set without 'ssh_bastion_host', so Packer must "+
+			"be run from a host that can reach the instance's private IP address.")
+	}
+	// Without a region in the OCID, Prepare can't check that the image is
+	// in the build's region.
+	if c.BaseImageID != "" && validateOCID(c.BaseImageID, "image") == nil && ocidRegion(c.BaseImageID) == "" {
+		warnings = append(warnings, fmt.Sprintf("'base_image_ocid' has no region, so Packer can't check "+
+			"that the image is in region %s.", c.Region))
+	}
+	return warnings
+}
+
+// validateCloudResources checks with read-only API calls that the base
+// image, subnet and shape of a prepared config exist and can be used to
+// launch an instance in its availability domain.
+func (c *Config) validateCloudResources(ctx context.Context, driver Driver) error {
+	// The existing instance was launched from them already.
+	if c.UseInstanceID != "" {
+		return nil
+	}
+
+	var errs *packersdk.MultiError
+
+	imageID := c.BaseImageID
+	if imageID != "" {
+		image, err := driver.GetImage(ctx, imageID)
+		if err != nil {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("Unable to get 'base_image_ocid' %q: %s", imageID, err))
+		} else if image.LifecycleState != core.ImageLifecycleStateAvailable {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+				"'base_image_ocid' %q is %s rather than AVAILABLE", imageID, image.LifecycleState))
+		}
+	} else if c.MarketplaceListingID != "" {
+		version, err := driver.GetMarketplaceListingVersion(ctx)
+		if err != nil {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("Unable to get 'marketplace_listing_ocid' %q: %s", c.MarketplaceListingID, err))
+		} else if version.ListingResourceId != nil {
+			imageID = *version.ListingResourceId
+		}
+	} else if c.BaseImageFilter.selectsImages() {
+		images, err := driver.ListImages(ctx)
+		if err != nil {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("Unable to list images for 'base_image_filter': %s", err))
+		} else if len(images) == 0 {
+			errs = packersdk.MultiErrorAppend(errs, errors.New("'base_image_filter' matches no images"))
+		} else {
+			imageID = *images[0].Id
+		}
+	}
+
+	var subnet *core.Subnet
+	if c.SubnetName != "" {
+		subnets, err := driver.ListSubnets(ctx)
+		if err != nil {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("Unable to list subnets for 'subnet_name': %s", err))
+		} else if len(subnets) != 1 {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+				"'subnet_name' %q matches %d available subnets usable in %s rather than one",
+				c.SubnetName, len(subnets), c.AvailabilityDomain))
+		} else {
+			subnet = &subnets[0]
+		}
+	} else if c.CreateVnicDetails.SubnetId != nil && *c.CreateVnicDetails.SubnetId != "" {
+		subnetID := *c.CreateVnicDetails.SubnetId
+		s, err := driver.GetSubnet(ctx, subnetID)
+		if err != nil {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("Unable to get 'subnet_ocid' %q: %s", subnetID, err))
+		} else if ad := s.AvailabilityDomain; ad != nil && *ad != "" && *ad != c.AvailabilityDomain {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+				"'subnet_ocid' %q is in availability domain %q but 'availability_domain' is %q",
+				subnetID, *ad, c.AvailabilityDomain))
+		} else {
+			subnet = &s
+		}
+	}
+	if subnet != nil && c.IPVersion == ipVersionIPv6 && subnet.Ipv6CidrBlock == nil {
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+			"'ip_version' is ipv6 but subnet %q has no IPv6 CIDR block", *subnet.Id))
+	}
+
+	if c.Shape != "" {
+		shapes, err := driver.ListShapes(ctx, imageID)
+		available := false
+		for _, shape := range shapes {
+			available = available || shape == c.Shape
+		}
+		if err != nil {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("Unable to list shapes: %s", err))
+		} else if !available {
+			msg := fmt.Sprintf("'shape' %q is not available in %s", c.Shape, c.AvailabilityDomain)
+			if imageID != "" {
+				msg += fmt.Sprintf(" for base image %q", imageID)
+			}
+			if closest := closestShapes(c.Shape, shapes, 3); len(closest) > 0 {
+				msg += ", the closest available shapes are " + strings.Join(closest, ", ")
+			}
+			errs = packersdk.MultiErrorAppend(errs, errors.New(msg))
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateProfile checks that the OCI config file at path, if there is one,
+// has the given profile. Otherwise the SDK silently ignores the file.
+func validateProfile(path, profile string) error {
+	path, err := pathing.ExpandUser(path)
+	if err != nil || path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+
+	cfg, err := ini.Load(path)
+	if err != nil {
+		return fmt.Errorf("Unable to parse access_cfg_file %s: %s", path, err)
+	}
+
+	profiles := cfg.SectionStrings()
+	for _, p := range profiles {
+		if p == profile {
+			return nil
+		}
+	}
+	return fmt.Errorf("access_cfg_file_account %q not found in access_cfg_file %s, available profiles are: %s",
+		profile, path, strings.Join(profiles, ", "))
+}
+
+// privateKeyError explains why the API signing key could not be loaded.
+func (c *Config) privateKeyError(keyContent []byte) error {
+	source, passPhrase, keyContent, err := c.signingKey(keyContent)
+	if err != nil {
+		return err
+	}
+
+	block, _ := pem.Decode(keyContent)
+	if block == nil {
+		return fmt.Errorf("%s is not a PEM encoded private key", source)
+	}
+	if x509.IsEncryptedPEMBlock(block) {
+		if passPhrase == "" {
+			return fmt.Errorf("%s is encrypted, 'pass_phrase' must be specified", source)
+		}
+		if _, err := x509.DecryptPEMBlock(block, []byte(passPhrase)); err != nil {
+			return fmt.Errorf("'pass_phrase' does not decrypt %s: %s", source, err)
+		}
+	}
+
+	if _, err := ocicommon.PrivateKeyFromBytes(keyContent, &passPhrase); err != nil {
+		return fmt.Errorf("%s is not a valid RSA private key: %s", source, err)
+	}
+	return fmt.Errorf("%s could not be loaded", source)
+}
+
+// buildConfigProvider returns the provider of the credentials to call the
+// OCI API with. That is the instance principal's, or else the template's,
+// falling back to the access_cfg_file profile's for the credentials the
+// template doesn't set. Without a region in either it defaults c.Region to
+// us-phoenix-1.
+func buildConfigProvider(c *Config) (ocicommon.ConfigurationProvider, error) {
+	if c.AuthType == authTypeInstancePrincipal {
+		// Tests assign a mock to c.configProvider as instance principals
+		// can only be obtained on an OCI instance.
+		if c.configProvider != nil {
+			return c.configProvider, nil
+		}
+		return ociauth.InstancePrincipalConfigurationProvider()
+	}
+
+	keyContent, err := c.templateSigningKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a config file every credential has to come from the
+	// template. When none of them do, a single error pointing at the
+	// ways to authenticate is clearer than one per missing option.
+	if c.AccessCfgFile == "" && c.UserID == "" && c.TenancyID == "" && c.Fingerprint == "" && keyContent == nil {
+		return nil, errors.New("No OCI credentials found: create ~/.oci/config or set 'access_cfg_file', " +
+			"set 'user_ocid', 'tenancy_ocid', 'fingerprint' and 'key_file' or 'key_content', " +
+			"or set 'use_instance_principals'")
+	}
+
+	var fileProvider ocicommon.ConfigurationProvider
+	if c.AccessCfgFile != "" {
+		// The default path is only used when it exists, so a missing
+		// file was set explicitly and is an error rather than a reason
+		// to fall back to the template's credentials.
+		if _, err := os.Stat(c.AccessCfgFile); err != nil {
+			return nil, fmt.Errorf("Unable to read access_cfg_file %s: %s", c.AccessCfgFile, err)
+		}
+		fileProvider, err = ocicommon.ConfigurationProviderFromFileWithProfile(c.AccessCfgFile, c.AccessCfgFileAccount, c.PassPhrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// An explicit region always wins over the OCI config file's, which
+	// in turn wins over the default. Resolving it here keeps c.Region in
+	// step with the region the SDK sends requests to.
+	if c.Region == "" && fileProvider != nil {
+		c.Region, _ = fileProvider.Region()
+	}
+	if c.Region == "" {
+		c.Region = "us-phoenix-1"
+	}
+
+	providers := []ocicommon.ConfigurationProvider{
+		NewRawConfigurationProvider(c.TenancyID, c.UserID, c.Region, c.Fingerprint, string(keyContent), &c.PassPhrase),
+	}
+
+	if fileProvider != nil {
+		providers = append(providers, fileProvider)
+	}
+
+	// Load API access configuration from SDK
+	return ocicommon.ComposingConfigurationProvider(providers)
+}
+
+// templateSigningKey reads the API signing key set in the template by
+// key_content or else key_file. It is nil if neither is set.
+func (c *Config) templateSigningKey() ([]byte, error) {
+	if c.KeyContent != "" {
+		return []byte(c.KeyContent), nil
+	}
+	if c.KeyFile == "" {
+		return nil, nil
+	}
+
+	path, err := pathing.ExpandUser(c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read API signing key
+	return ioutil.ReadFile(path)
+}
+
+// validatePassPhrase checks that a pass_phrase is only given for an encrypted
+// API signing key. The SDK ignores it otherwise, hiding a misconfiguration.
+func (c *Config) validatePassPhrase(keyContent []byte) error {
+	if c.PassPhrase == "" {
+		return nil
+	}
+	source, _, keyContent, err := c.signingKey(keyContent)
+	if err != nil {
+		return nil
+	}
+	if block, _ := pem.Decode(keyContent); block != nil && !x509.IsEncryptedPEMBlock(block) {
+		return fmt.Errorf("'pass_phrase' is specified but %s is not encrypted", source)
+	}
+	return nil
+}
+
+// signingKey describes where the API signing key comes from and returns its
+// pass phrase and content. The key is key_content, key_file or else the
+// key_file of the access_cfg_file profile.
+func (c *Config) signingKey(keyContent []byte) (string, string, []byte, error) {
+	if c.KeyContent != "" {
+		return "'key_content'", c.PassPhrase, keyContent, nil
+	}
+	if c.KeyFile != "" {
+		return fmt.Sprintf("'key_file' %s", c.KeyFile), c.PassPhrase, keyContent, nil
+	}
+
+	keyFile, passPhrase := profileKeyFile(c.AccessCfgFile, c.AccessCfgFileAccount)
+	if keyFile == "" {
+		return "", "", nil, errors.New("'key_file' or 'key_content' must be specified")
+	}
+	if c.PassPhrase != "" {
+		passPhrase = c.PassPhrase
+	}
+
+	path, err := pathing.ExpandUser(keyFile)
+	if err == nil {
+		keyContent, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return "", "", nil, fmt.Errorf("Unable to read 'key_file' %s: %s", keyFile, err)
+	}
+	return fmt.Sprintf("'key_file' %s", keyFile), passPhrase, keyContent, nil
+}
+
+// profileKeyFile returns the key_file and pass_phrase of a profile of the OCI
+// config file at path, if there is one.
+func profileKeyFile(path, profile string) (string, string) {
+	path, err := pathing.ExpandUser(path)
+	if err != nil || path == "" {
+		return "", ""
+	}
+	cfg, err := ini.Load(path)
+	if err != nil {
+		return "", ""
+	}
+	section := cfg.Section(profile)
+	return section.Key("key_file").String(), section.Key("pass_phrase").String()
+}
+
+// resolvConfUserData renders a cloud-init user_data that configures the
+// instance's resolv.conf before any provisioner runs.
+func resolvConfUserData(nameservers, searchDomains []string) string {
+	// JSON arrays are valid YAML flow sequences, which saves quoting.
+	quote := func(values []string) string {
+		if values == nil {
+			values = []string{}
+		}
+		b, _ := json.Marshal(values)
+		return string(b)
+	}
+
+	return fmt.Sprintf(`#cloud-config
+manage_resolv_conf: true
+resolv_conf:
+  nameservers: %s
+  searchdomains: %s
+`, quote(nameservers), quote(searchDomains))
+}
+
+// hostnameLabelRegexp matches a valid VNIC hostname label as per RFC 952 and
+// RFC 1123.
+// defaultImageNameRegexp matches the characters that image names built from
+// image_name_prefix and image_name_timestamp_format may contain.
+var defaultImageNameRegexp = regexp.MustCompile(`^[\w .:+-]+$`)
+
+// validateTimestampFormat checks that a Go time layout formats two times that
+// differ in every field differently, so that each build gets a new image name.
+func validateTimestampFormat(layout string) error {
+	first := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	second := time.Date(2017, time.December, 25, 8, 37, 48, 0, time.UTC)
+	if first.Format(layout) == second.Format(layout) {
+		return fmt.Errorf("'image_name_timestamp_format' %q does not format any part of the time, "+
+			"so every build would get the same image name", layout)
+	}
+	return nil
+}
+
+var hostnameLabelRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// findCompartmentID returns the OCID of the active compartment called name
+// anywhere under parentID, usually the tenancy. Compartment names are only
+// unique among siblings so it is an error for more than one to match.
+func findCompartmentID(ctx context.Context, client identityClient, parentID, name string) (string, error) {
+	accessLevel := "ACCESSIBLE"
+	inSubtree := true
+	var ids []string
+	var page *string
+	for {
+		response, err := client.ListCompartments(ctx, listCompartmentsRequest{
+			CompartmentId:          &parentID,
+			AccessLevel:            &accessLevel,
+			CompartmentIdInSubtree: &inSubtree,
+			Name:                   &name,
+			Page:                   page,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		for _, compartment := range response.Items {
+			if compartment.LifecycleState == "ACTIVE" && *compartment.Name == name {
+				ids = append(ids, *compartment.Id)
+			}
+		}
+
+		if response.OpcNextPage == nil {
+			break
+		}
+		page = response.OpcNextPage
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", errors.New("no active compartment with that name was found")
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("%d compartments have that name, specify 'compartment_ocid' instead: %s",
+			len(ids), strings.Join(ids, ", "))
+	}
+}
+
+// closestShapes returns up to n of shapes, closest to shape first by edit
+// distance, to suggest in place of an unavailable shape.
+func closestShapes(shape string, shapes []string, n int) []string {
+	distances := map[string]int{}
+	for _, s := range shapes {
+		distances[s] = editDistance(strings.ToLower(shape), strings.ToLower(s))
+	}
+
+	closest := make([]string, 0, len(distances))
+	for s := range distances {
+		closest = append(closest, s)
+	}
+	sort.Slice(closest, func(i, j int) bool {
+		if distances[closest[i]] != distances[closest[j]] {
+			return distances[closest[i]] < distances[closest[j]]
+		}
+		return closest[i] < closest[j]
+	})
+
+	if len(closest) > n {
+		closest = closest[:n]
+	}
+	return closest
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// isBareMetalShape reports whether shape is a bare metal rather than virtual
+// machine shape.
+func isBareMetalShape(shape string) bool {
+	return strings.HasPrefix(shape, "BM.")
+}
+
+// shieldedPlatformTypes are the platform config types of the shapes that
+// support shielded instances, by shape or shape series prefix.
+var shieldedPlatformTypes = []struct{ prefix, platformType string }{
+	{"VM.Standard2.", "INTEL_VM"},
+	{"VM.DenseIO2.", "INTEL_VM"},
+	{"VM.Standard3.Flex", "INTEL_VM"},
+	{"VM.Optimized3.Flex", "INTEL_VM"},
+	{"VM.Standard.E3.Flex", "AMD_VM"},
+	{"VM.Standard.E4.Flex", "AMD_VM"},
+	{"BM.Standard2.52", "INTEL_SKYLAKE_BM"},
+	{"BM.DenseIO2.52", "INTEL_SKYLAKE_BM"},
+	{"BM.Standard.E3.128", "AMD_ROME_BM"},
+	{"BM.Standard.E4.128", "AMD_MILAN_BM"},
+	{"BM.DenseIO.E4.128", "AMD_MILAN_BM"},
+}
+
+// shieldedPlatformType returns the platform config type to launch a shielded
+// instance of shape with, or "" if the shape doesn't support them.
+func shieldedPlatformType(shape string) string {
+	for _, t := range shieldedPlatformTypes {
+		if strings.HasPrefix(shape, t.prefix) {
+			return t.platformType
+		}
+	}
+	return ""
+}
+
+// validateOneOf checks that the value of key is one of allowed.
+func validateOneOf(key, value string, allowed []string) error {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("'%s' must be one of %s, found %q", key, strings.Join(allowed, ", "), value)
+}
+
+// validateOCID checks that value is an OCID of the given resource type, i.e.
+// of the form ocid1.<resource type>.<realm>.[region].<unique ID>. The region
+// is empty for global resources such as tenancies and users.
+func validateOCID(value, resourceType string) error {
+	parts := strings.Split(value, ".")
+	if len(parts) < 5 || parts[0] != "ocid1" || parts[2] == "" || parts[len(parts)-1] == "" {
+		return fmt.Errorf("must be an OCID of the form ocid1.%s.<realm>.[region].<unique ID>, found %q", resourceType, value)
+	}
+	if parts[1] != resourceType {
+		return fmt.Errorf("must be an OCID of type %s, found an OCID of type %s %q", resourceType, parts[1], value)
+	}
+	return nil
+}
+
+// ocidRegion returns the region of an OCID of the form
+// ocid1.<resource type>.<realm>.[region].<unique ID>, which is empty for
+// global resources and for values that aren't OCIDs.
+func ocidRegion(value string) string {
+	parts := strings.Split(value, ".")
+	if len(parts) < 5 || parts[0] != "ocid1" {
+		return ""
+	}
+	return parts[3]
+}
+
+// knownRegions are the regions the OCI SDK knows the endpoints of.
+var knownRegions = []ocicommon.Region{
+	ocicommon.RegionSEA,
+	ocicommon.RegionCAToronto1,
+	ocicommon.RegionCAMontreal1,
+	ocicommon.RegionPHX,
+	ocicommon.RegionIAD,
+	ocicommon.RegionSJC1,
+	ocicommon.RegionFRA,
+	ocicommon.RegionLHR,
+	ocicommon.RegionAPTokyo1,
+	ocicommon.RegionAPOsaka1,
+	ocicommon.RegionAPChiyoda1,
+	ocicommon.RegionAPSeoul1,
+	ocicommon.RegionAPChuncheon1,
+	ocicommon.RegionAPMumbai1,
+	ocicommon.RegionAPHyderabad1,
+	ocicommon.RegionAPMelbourne1,
+	ocicommon.RegionAPSydney1,
+	ocicommon.RegionMEJeddah1,
+	ocicommon.RegionEUZurich1,
+	ocicommon.RegionEUAmsterdam1,
+	ocicommon.RegionSASaopaulo1,
+	ocicommon.RegionUSLangley1,
+	ocicommon.RegionUSLuke1,
+	ocicommon.RegionUSGovAshburn1,
+	ocicommon.RegionUSGovChicago1,
+	ocicommon.RegionUSGovPhoenix1,
+	ocicommon.RegionUKGovLondon1,
+	ocicommon.RegionUKGovCardiff1,
+}
+
+// validateRegion checks that region, either its name or short code, is known
+// to the OCI SDK so that typos are caught before any API call is made.
+func validateRegion(region string) error {
+	r := ocicommon.StringToRegion(region)
+	for _, known := range knownRegions {
+		if r == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("'region' %q is not a known OCI region, expected a region such as %q, %q or %q",
+		region, ocicommon.RegionPHX, ocicommon.RegionIAD, ocicommon.RegionFRA)
+}
+
+// templateDir is the directory of the template that configured the build,
+// or "" if Packer didn't pass it on.
+func (c *Config) templateDir() string {
+	if c.ctx.TemplatePath == "" {
+		return ""
+	}
+	return filepath.Dir(c.ctx.TemplatePath)
+}
+
+// expandPath expands a leading ~ to the user's home directory and makes a
+// relative path absolute, relative to dir or else the current working
+// directory.
+func expandPath(path, dir string) (string, error) {
+	path, err := pathing.ExpandUser(path)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) && dir != "" {
+		path = filepath.Join(dir, path)
+	}
+	return filepath.Abs(path)
+}
+
 // getDefaultOCISettingsPath uses os/user to compute the default
 // config file location ($HOME/.oci/config).
 func getDefaultOCISettingsPath() (string, error) {
@@ -8,11 +8,38 @@ import (
 
 // Driver interfaces between the builder steps and the OCI SDK.
 type Driver interface {
+	AttachVnic(ctx context.Context, instanceID, displayName, subnetID string, nsgIDs []string) (string, error)
+	AttachVolume(ctx context.Context, instanceID, volumeID, attachmentType string) (string, error)
+	ChangeImageCompartment(ctx context.Context, id, compartmentID string) error
+	CopyImageCapabilitySchema(ctx context.Context, schemaID, imageID string) (string, error)
 	CreateInstance(ctx context.Context, publicKey string) (string, error)
 	CreateImage(ctx context.Context, id string) (core.Image, error)
+	CreateVolume(ctx context.Context, displayName string, sizeInGBs int64, freeformTags map[string]string, definedTags map[string]map[string]interface{}) (string, error)
 	DeleteImage(ctx context.Context, id string) error
+	DeleteVolume(ctx context.Context, id string) error
+	DetachVnic(ctx context.Context, attachmentID string) error
+	DetachVolume(ctx context.Context, attachmentID string) error
+	ExportImage(ctx context.Context, id string) (string, error)
+	GetDedicatedVmHost(ctx context.Context, id string) (core.DedicatedVmHost, error)
+	GetImage(ctx context.Context, id string) (core.Image, error)
+	GetInstanceInitialCredentials(ctx context.Context, id string) (string, string, error)
 	GetInstanceIP(ctx context.Context, id string) (string, error)
+	GetMarketplaceListingVersion(ctx context.Context) (core.AppCatalogListingResourceVersion, error)
+	GetSubnet(ctx context.Context, id string) (core.Subnet, error)
+	ImportImage(ctx context.Context) (core.Image, error)
+	ListAvailabilityDomains(ctx context.Context) ([]string, error)
+	ListImages(ctx context.Context) ([]core.Image, error)
+	ListInstancesByTag(ctx context.Context, tagKey, tagValue string) ([]string, error)
+	ListShapes(ctx context.Context, imageID string) ([]string, error)
+	ListSubnets(ctx context.Context) ([]core.Subnet, error)
+	ListTagDefaults(ctx context.Context, compartmentID string) (map[string]map[string]interface{}, error)
+	ListTagNamespaces(ctx context.Context) ([]string, error)
+	RemovePublicIP(ctx context.Context, instanceID string) error
+	SetImageOperatingSystem(ctx context.Context, id, operatingSystem, version string) error
+	StopInstance(ctx context.Context, id string) error
+	SubscribeMarketplaceListing(ctx context.Context, version string) error
 	TerminateInstance(ctx context.Context, id string) error
+	UpdateImage(ctx context.Context, id string, freeformTags map[string]string, definedTags map[string]map[string]interface{}) (core.Image, error)
 	WaitForImageCreation(ctx context.Context, id string) error
-	WaitForInstanceState(ctx context.Context, id string, waitStates []string, terminalState string) error
+	WaitForInstanceState(ctx context.Context, id string, waitStates, terminalStates []string) (string, error)
 }
@@ -6,23 +6,112 @@ import (
 	"github.com/oracle/oci-go-sdk/core"
 )
 
-// driverMock implements the Driver interface and communicates with Oracle
-// OCI.
+var _ Driver = &driverMock{}
+
+// driverMock implements the Driver interface for the step tests without
+// calling OCI. Each method records its arguments in the <Method><Arg> fields
+// and fails with <Method>Err when it is set.
 type driverMock struct {
-	CreateInstanceID  string
-	CreateInstanceErr error
+	AttachVnicIDs []string
+	AttachVnicErr error
+
+	AttachVolumeIDs []string
+	AttachVolumeErr error
+
+	ChangeImageCompartmentID            string
+	ChangeImageCompartmentCompartmentID string
+	ChangeImageCompartmentErr           error
+
+	CopyImageCapabilitySchemaImageID string
+	CopyImageCapabilitySchemaErr     error
+
+	CreateInstanceID        string
+	CreateInstancePublicKey string
+	CreateInstanceErr       error
 
 	CreateImageID  string
 	CreateImageErr error
 
+	CreateVolumeIDs []string
+	CreateVolumeErr error
+
 	DeleteImageID  string
 	DeleteImageErr error
 
+	DeleteVolumeIDs []string
+	DeleteVolumeErr error
+
+	DetachVnicIDs []string
+	DetachVnicErr error
+
+	DetachVolumeIDs []string
+	DetachVolumeErr error
+
+	ExportImageID  string
+	ExportImageErr error
+
+	GetDedicatedVmHostAvailabilityDomain string
+	GetDedicatedVmHostErr                error
+
+	GetImageSizeInMBs int64
+	GetImageErr       error
+
+	GetInstanceInitialCredentialsErr error
+
 	GetInstanceIPErr error
 
+	GetMarketplaceListingVersionAllowedActions []core.AppCatalogListingResourceVersionAllowedActionsEnum
+	GetMarketplaceListingVersionShapes         []string
+	GetMarketplaceListingVersionErr            error
+
+	GetSubnetAvailabilityDomain string
+	GetSubnetIpv6CidrBlock      string
+	GetSubnetErr                error
+
+	ImportImageID  string
+	ImportImageErr error
+
+	ListAvailabilityDomainsNames []string
+	ListAvailabilityDomainsErr   error
+
+	ListImagesImages []core.Image
+	ListImagesErr    error
+
+	ListInstancesByTagIDs []string
+	ListInstancesByTagErr error
+
+	ListShapesShapes []string
+	ListShapesErr    error
+
+	ListSubnetsSubnets []core.Subnet
+	ListSubnetsErr     error
+
+	ListTagDefaultsTags map[string]map[string]interface{}
+	ListTagDefaultsErr  error
+
+	ListTagNamespacesNames []string
+	ListTagNamespacesErr   error
+
+	RemovePublicIPID  string
+	RemovePublicIPErr error
+
+	SetImageOperatingSystemID  string
+	SetImageOperatingSystemErr error
+
+	StopInstanceID  string
+	StopInstanceErr error
+
+	SubscribeMarketplaceListingVersion string
+	SubscribeMarketplaceListingErr     error
+
 	TerminateInstanceID  string
 	TerminateInstanceErr error
 
+	UpdateImageID           string
+	UpdateImageFreeformTags map[string]string
+	UpdateImageDefinedTags  map[string]map[string]interface{}
+	UpdateImageErr          error
+
 	WaitForImageCreationErr error
 
 	WaitForInstanceStateErr error
@@ -30,6 +119,54 @@ type driverMock struct {
 	cfg *Config
 }
 
+// AttachVnic mocks attaching a secondary VNIC to an instance.
+func (d *driverMock) AttachVnic(ctx context.Context, instanceID, displayName, subnetID string, nsgIDs []string) (string, error) {
+	if d.AttachVnicErr != nil {
+		return "", d.AttachVnicErr
+	}
+
+	id := "ocid1.vnicattachment." + displayName
+	d.AttachVnicIDs = append(d.AttachVnicIDs, id)
+
+	return id, nil
+}
+
+// AttachVolume mocks attaching a block volume to an instance.
+func (d *driverMock) AttachVolume(ctx context.Context, instanceID, volumeID, attachmentType string) (string, error) {
+	if d.AttachVolumeErr != nil {
+		return "", d.AttachVolumeErr
+	}
+
+	id := "ocid1.volumeattachment." + volumeID
+	d.AttachVolumeIDs = append(d.AttachVolumeIDs, id)
+
+	return id, nil
+}
+
+// ChangeImageCompartment mocks moving a custom image to another compartment.
+func (d *driverMock) ChangeImageCompartment(ctx context.Context, id, compartmentID string) error {
+	if d.ChangeImageCompartmentErr != nil {
+		return d.ChangeImageCompartmentErr
+	}
+
+	d.ChangeImageCompartmentID = id
+	d.ChangeImageCompartmentCompartmentID = compartmentID
+
+	return nil
+}
+
+// CopyImageCapabilitySchema mocks giving a custom image the capabilities of
+// an existing image capability schema.
+func (d *driverMock) CopyImageCapabilitySchema(ctx context.Context, schemaID, imageID string) (string, error) {
+	if d.CopyImageCapabilitySchemaErr != nil {
+		return "", d.CopyImageCapabilitySchemaErr
+	}
+
+	d.CopyImageCapabilitySchemaImageID = imageID
+
+	return "ocid1.computeimagecapabilityschema.oc1..copy", nil
+}
+
 // CreateInstance creates a new compute instance.
 func (d *driverMock) CreateInstance(ctx context.Context, publicKey string) (string, error) {
 	if d.CreateInstanceErr != nil {
@@ -37,6 +174,7 @@ func (d *driverMock) CreateInstance(ctx context.Context, publicKey string) (stri
 	}
 
 	d.CreateInstanceID = "ocid1..."
+	d.CreateInstancePublicKey = publicKey
 
 	return d.CreateInstanceID, nil
 }
@@ -50,6 +188,18 @@ func (d *driverMock) CreateImage(ctx context.Context, id string) (core.Image, er
 	return core.Image{Id: &id}, nil
 }
 
+// CreateVolume mocks creating a block volume.
+func (d *driverMock) CreateVolume(ctx context.Context, displayName string, sizeInGBs int64, freeformTags map[string]string, definedTags map[string]map[string]interface{}) (string, error) {
+	if d.CreateVolumeErr != nil {
+		return "", d.CreateVolumeErr
+	}
+
+	id := displayName
+	d.CreateVolumeIDs = append(d.CreateVolumeIDs, id)
+
+	return id, nil
+}
+
 // DeleteImage mocks deleting a custom image.
 func (d *driverMock) DeleteImage(ctx context.Context, id string) error {
 	if d.DeleteImageErr != nil {
@@ -61,6 +211,85 @@ func (d *driverMock) DeleteImage(ctx context.Context, id string) error {
 	return nil
 }
 
+// DeleteVolume mocks deleting a block volume.
+func (d *driverMock) DeleteVolume(ctx context.Context, id string) error {
+	if d.DeleteVolumeErr != nil {
+		return d.DeleteVolumeErr
+	}
+
+	d.DeleteVolumeIDs = append(d.DeleteVolumeIDs, id)
+
+	return nil
+}
+
+// DetachVnic mocks detaching a secondary VNIC from an instance.
+func (d *driverMock) DetachVnic(ctx context.Context, attachmentID string) error {
+	if d.DetachVnicErr != nil {
+		return d.DetachVnicErr
+	}
+
+	d.DetachVnicIDs = append(d.DetachVnicIDs, attachmentID)
+
+	return nil
+}
+
+// DetachVolume mocks detaching a block volume from an instance.
+func (d *driverMock) DetachVolume(ctx context.Context, attachmentID string) error {
+	if d.DetachVolumeErr != nil {
+		return d.DetachVolumeErr
+	}
+
+	d.DetachVolumeIDs = append(d.DetachVolumeIDs, attachmentID)
+
+	return nil
+}
+
+// ExportImage mocks exporting a custom image to Object Storage.
+func (d *driverMock) ExportImage(ctx context.Context, id string) (string, error) {
+	if d.ExportImageErr != nil {
+		return "", d.ExportImageErr
+	}
+
+	d.ExportImageID = id
+
+	return "https://objectstorage.us-ashburn-1.oraclecloud.com/n/namespace/b/bucket/o/image", nil
+}
+
+// GetDedicatedVmHost mocks getting a dedicated virtual machine host. The host
+// is in the configured availability domain unless
+// GetDedicatedVmHostAvailabilityDomain is set.
+func (d *driverMock) GetDedicatedVmHost(ctx context.Context, id string) (core.DedicatedVmHost, error) {
+	if d.GetDedicatedVmHostErr != nil {
+		return core.DedicatedVmHost{}, d.GetDedicatedVmHostErr
+	}
+	ad := d.cfg.AvailabilityDomain
+	if d.GetDedicatedVmHostAvailabilityDomain != "" {
+		ad = d.GetDedicatedVmHostAvailabilityDomain
+	}
+	return core.DedicatedVmHost{Id: &id, AvailabilityDomain: &ad}, nil
+}
+
+// GetImage mocks getting a custom image.
+func (d *driverMock) GetImage(ctx context.Context, id string) (core.Image, error) {
+	if d.GetImageErr != nil {
+		return core.Image{}, d.GetImageErr
+	}
+	image := core.Image{Id: &id, LifecycleState: core.ImageLifecycleStateAvailable}
+	if d.GetImageSizeInMBs != 0 {
+		image.SizeInMBs = &d.GetImageSizeInMBs
+	}
+	return image, nil
+}
+
+// GetInstanceInitialCredentials mocks getting the initial credentials of a
+// Windows instance.
+func (d *driverMock) GetInstanceInitialCredentials(ctx context.Context, id string) (string, string, error) {
+	if d.GetInstanceInitialCredentialsErr != nil {
+		return "", "", d.GetInstanceInitialCredentialsErr
+	}
+	return "opc", "password", nil
+}
+
 // GetInstanceIP returns the public or private IP corresponding to the given instance id.
 func (d *driverMock) GetInstanceIP(ctx context.Context, id string) (string, error) {
 	if d.GetInstanceIPErr != nil {
@@ -72,6 +301,171 @@ func (d *driverMock) GetInstanceIP(ctx context.Context, id string) (string, erro
 	return "ip", nil
 }
 
+// GetMarketplaceListingVersion mocks getting the version of the Marketplace
+// listing to launch from, which allows snapshots unless
+// GetMarketplaceListingVersionAllowedActions is set.
+func (d *driverMock) GetMarketplaceListingVersion(ctx context.Context) (core.AppCatalogListingResourceVersion, error) {
+	if d.GetMarketplaceListingVersionErr != nil {
+		return core.AppCatalogListingResourceVersion{}, d.GetMarketplaceListingVersionErr
+	}
+
+	version := d.cfg.MarketplacePackageVersion
+	if version == "" {
+		version = "1.0"
+	}
+	imageID := "ocid1.image.oc1..marketplace"
+	allowedActions := d.GetMarketplaceListingVersionAllowedActions
+	if allowedActions == nil {
+		allowedActions = []core.AppCatalogListingResourceVersionAllowedActionsEnum{
+			core.AppCatalogListingResourceVersionAllowedActionsSnapshot,
+		}
+	}
+
+	return core.AppCatalogListingResourceVersion{
+		ListingId:              &d.cfg.MarketplaceListingID,
+		ListingResourceId:      &imageID,
+		ListingResourceVersion: &version,
+		AllowedActions:         allowedActions,
+		CompatibleShapes:       d.GetMarketplaceListingVersionShapes,
+	}, nil
+}
+
+// GetSubnet mocks getting a subnet. The subnet is regional unless
+// GetSubnetAvailabilityDomain is set, and IPv4 only unless
+// GetSubnetIpv6CidrBlock is set.
+func (d *driverMock) GetSubnet(ctx context.Context, id string) (core.Subnet, error) {
+	if d.GetSubnetErr != nil {
+		return core.Subnet{}, d.GetSubnetErr
+	}
+	subnet := core.Subnet{Id: &id, LifecycleState: core.SubnetLifecycleStateAvailable}
+	if d.GetSubnetAvailabilityDomain != "" {
+		subnet.AvailabilityDomain = &d.GetSubnetAvailabilityDomain
+	}
+	if d.GetSubnetIpv6CidrBlock != "" {
+		subnet.Ipv6CidrBlock = &d.GetSubnetIpv6CidrBlock
+	}
+	return subnet, nil
+}
+
+// ImportImage mocks importing the base image from Object Storage.
+func (d *driverMock) ImportImage(ctx context.Context) (core.Image, error) {
+	if d.ImportImageErr != nil {
+		return core.Image{}, d.ImportImageErr
+	}
+
+	d.ImportImageID = "ocid1.image.oc1..imported"
+
+	return core.Image{Id: &d.ImportImageID}, nil
+}
+
+// ListAvailabilityDomains mocks listing the availability domains. The
+// configured availability domain is listed unless
+// ListAvailabilityDomainsNames is set.
+func (d *driverMock) ListAvailabilityDomains(ctx context.Context) ([]string, error) {
+	if d.ListAvailabilityDomainsErr != nil {
+		return nil, d.ListAvailabilityDomainsErr
+	}
+	if d.ListAvailabilityDomainsNames != nil {
+		return d.ListAvailabilityDomainsNames, nil
+	}
+	return []string{d.cfg.AvailabilityDomain}, nil
+}
+
+// ListImages mocks listing the images matching the base image filter.
+func (d *driverMock) ListImages(ctx context.Context) ([]core.Image, error) {
+	if d.ListImagesErr != nil {
+		return nil, d.ListImagesErr
+	}
+	return d.ListImagesImages, nil
+}
+
+// ListInstancesByTag mocks listing the instances with a freeform tag.
+func (d *driverMock) ListInstancesByTag(ctx context.Context, tagKey, tagValue string) ([]string, error) {
+	if d.ListInstancesByTagErr != nil {
+		return nil, d.ListInstancesByTagErr
+	}
+	return d.ListInstancesByTagIDs, nil
+}
+
+// ListShapes mocks listing the shapes available in the availability domain.
+// Only the configured shape is available unless ListShapesShapes is set.
+func (d *driverMock) ListShapes(ctx context.Context, imageID string) ([]string, error) {
+	if d.ListShapesErr != nil {
+		return nil, d.ListShapesErr
+	}
+	if d.ListShapesShapes != nil {
+		return d.ListShapesShapes, nil
+	}
+	return []string{d.cfg.Shape}, nil
+}
+
+// ListSubnets mocks listing the subnets named subnet_name.
+func (d *driverMock) ListSubnets(ctx context.Context) ([]core.Subnet, error) {
+	if d.ListSubnetsErr != nil {
+		return nil, d.ListSubnetsErr
+	}
+	return d.ListSubnetsSubnets, nil
+}
+
+// ListTagDefaults mocks getting the tag defaults of a compartment.
+func (d *driverMock) ListTagDefaults(ctx context.Context, compartmentID string) (map[string]map[string]interface{}, error) {
+	if d.ListTagDefaultsErr != nil {
+		return nil, d.ListTagDefaultsErr
+	}
+	return d.ListTagDefaultsTags, nil
+}
+
+// ListTagNamespaces mocks listing the tag namespaces in the tenancy.
+func (d *driverMock) ListTagNamespaces(ctx context.Context) ([]string, error) {
+	if d.ListTagNamespacesErr != nil {
+		return nil, d.ListTagNamespacesErr
+	}
+	return d.ListTagNamespacesNames, nil
+}
+
+// RemovePublicIP mocks removing the public IP of an instance's primary VNIC.
+func (d *driverMock) RemovePublicIP(ctx context.Context, instanceID string) error {
+	if d.RemovePublicIPErr != nil {
+		return d.RemovePublicIPErr
+	}
+	d.RemovePublicIPID = instanceID
+	return nil
+}
+
+// SetImageOperatingSystem mocks setting the OS of a custom image.
+func (d *driverMock) SetImageOperatingSystem(ctx context.Context, id, operatingSystem, version string) error {
+	if d.SetImageOperatingSystemErr != nil {
+		return d.SetImageOperatingSystemErr
+	}
+
+	d.SetImageOperatingSystemID = id
+
+	return nil
+}
+
+// StopInstance mocks gracefully stopping an instance.
+func (d *driverMock) StopInstance(ctx context.Context, id string) error {
+	if d.StopInstanceErr != nil {
+		return d.StopInstanceErr
+	}
+
+	d.StopInstanceID = id
+
+	return nil
+}
+
+// SubscribeMarketplaceListing mocks accepting the terms of a version of the
+// Marketplace listing.
+func (d *driverMock) SubscribeMarketplaceListing(ctx context.Context, version string) error {
+	if d.SubscribeMarketplaceListingErr != nil {
+		return d.SubscribeMarketplaceListingErr
+	}
+
+	d.SubscribeMarketplaceListingVersion = version
+
+	return nil
+}
+
 // TerminateInstance terminates a compute instance.
 func (d *driverMock) TerminateInstance(ctx context.Context, id string) error {
 	if d.TerminateInstanceErr != nil {
@@ -83,14 +477,30 @@ func (d *driverMock) TerminateInstance(ctx context.Context, id string) error {
 	return nil
 }
 
+// UpdateImage mocks merging tags into those of a custom image.
+func (d *driverMock) UpdateImage(ctx context.Context, id string, freeformTags map[string]string, definedTags map[string]map[string]interface{}) (core.Image, error) {
+	if d.UpdateImageErr != nil {
+		return core.Image{}, d.UpdateImageErr
+	}
+
+	d.UpdateImageID = id
+	d.UpdateImageFreeformTags = freeformTags
+	d.UpdateImageDefinedTags = definedTags
+
+	return core.Image{Id: &id, FreeformTags: freeformTags, DefinedTags: definedTags}, nil
+}
+
 // WaitForImageCreation waits for a provisioning custom image to reach the
 // "AVAILABLE" state.
 func (d *driverMock) WaitForImageCreation(ctx context.Context, id string) error {
 	return d.WaitForImageCreationErr
 }
 
-// WaitForInstanceState waits for an instance to reach the a given terminal
-// state.
-func (d *driverMock) WaitForInstanceState(ctx context.Context, id string, waitStates []string, terminalState string) error {
-	return d.WaitForInstanceStateErr
+// WaitForInstanceState waits for an instance to reach one of the given
+// terminal states, settling in the first of them.
+func (d *driverMock) WaitForInstanceState(ctx context.Context, id string, waitStates, terminalStates []string) (string, error) {
+	if d.WaitForInstanceStateErr != nil {
+		return "", d.WaitForInstanceStateErr
+	}
+	return terminalStates[0], nil
 }
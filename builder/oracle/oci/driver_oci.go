@@ -2,55 +2,97 @@ package oci
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
+	"log"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"regexp"
-	"sync/atomic"
+	"sort"
+	"strings"
 	"time"
 
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
 	"github.com/oracle/oci-go-sdk/common"
 	core "github.com/oracle/oci-go-sdk/core"
 )
 
+var _ Driver = &driverOCI{}
+
 // driverOCI implements the Driver interface and communicates with Oracle
 // OCI.
 type driverOCI struct {
-	computeClient core.ComputeClient
-	vcnClient     core.VirtualNetworkClient
-	cfg           *Config
-	context       context.Context
+	computeClient      core.ComputeClient
+	vcnClient          core.VirtualNetworkClient
+	blockstorageClient core.BlockstorageClient
+	identityClient     identityClient
+	cfg                *Config
+	context            context.Context
+	ui                 packersdk.Ui
+
+	requestMetadata common.RequestMetadata
+}
+
+// maxRetryDelay caps the delay between retries of a failed request, so that
+// a request retried many times doesn't wait for many minutes.
+const maxRetryDelay = 30 * time.Second
+
+// newRequestMetadata returns request metadata with a retry policy that
+// retries errors with one of the given HTTP status codes up to maxRetries
+// times, backing off exponentially with jitter between attempts. Other errors
+// fail immediately.
+//
+// The vendored SDK only takes a retry policy per request rather than per
+// client, so every request the driver makes is given this metadata.
+func newRequestMetadata(maxRetries int, statusCodes []int) common.RequestMetadata {
+	return common.RequestMetadata{
+		RetryPolicy: &common.RetryPolicy{
+			MaximumNumberAttempts: uint(maxRetries) + 1,
+			ShouldRetryOperation:  shouldRetryOperation(statusCodes),
+			NextDuration: func(res common.OCIOperationResponse) time.Duration {
+				return retryDelay(res.AttemptNumber)
+			},
+		},
+	}
+}
+
+// retryDelay returns the delay before retrying a request that failed on the
+// given attempt: 2^attempt seconds plus up to 2 seconds of jitter, at most
+// maxRetryDelay.
+func retryDelay(attempt uint) time.Duration {
+	delay := time.Second
+	for i := uint(0); i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	delay += time.Duration(rand.Float64()*2000) * time.Millisecond
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
 }
 
-var retryPolicy = &common.RetryPolicy{
-	MaximumNumberAttempts: 10,
-	ShouldRetryOperation: func(res common.OCIOperationResponse) bool {
+// shouldRetryOperation returns a function reporting whether a failed request
+// may succeed if it is retried, which is when it failed with one of the given
+// HTTP status codes.
+func shouldRetryOperation(statusCodes []int) func(common.OCIOperationResponse) bool {
+	return func(res common.OCIOperationResponse) bool {
 		var e common.ServiceError
 		if errors.As(res.Error, &e) {
-			switch e.GetHTTPStatusCode() {
-			case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable:
-				return true
+			for _, code := range statusCodes {
+				if e.GetHTTPStatusCode() == code {
+					return true
+				}
 			}
 		}
 		return false
-	},
-	NextDuration: func(res common.OCIOperationResponse) time.Duration {
-		x := uint64(res.AttemptNumber)
-		d := time.Duration(math.Pow(2, float64(atomic.LoadUint64(&x)))) * time.Second
-		j := time.Duration(rand.Float64()*(2000)) * time.Millisecond
-		w := d + j
-		return w
-	},
-}
-
-var requestMetadata = common.RequestMetadata{
-	RetryPolicy: retryPolicy,
+	}
 }
 
 // NewDriverOCI Creates a new driverOCI with a connected compute client and a connected vcn client.
-func NewDriverOCI(cfg *Config) (Driver, error) {
+// The progress of long waits is reported to ui, if any.
+func NewDriverOCI(cfg *Config, ui packersdk.Ui) (Driver, error) {
 	coreClient, err := core.NewComputeClientWithConfigurationProvider(cfg.configProvider)
 	if err != nil {
 		return nil, err
@@ -61,26 +103,69 @@ func NewDriverOCI(cfg *Config) (Driver, error) {
 		return nil, err
 	}
 
+	blockstorageClient, err := core.NewBlockstorageClientWithConfigurationProvider(cfg.configProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	identityClient, err := newIdentityClient(cfg.configProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, client := range []*common.BaseClient{
+		&coreClient.BaseClient,
+		&vcnClient.BaseClient,
+		&blockstorageClient.BaseClient,
+		&identityClient.BaseClient,
+	} {
+		setHTTPTimeout(client, cfg.HTTPTimeout)
+	}
+
+	if cfg.ServiceEndpointOverride != "" {
+		coreClient.Host = cfg.ServiceEndpointOverride
+		vcnClient.Host = cfg.ServiceEndpointOverride
+		blockstorageClient.Host = cfg.ServiceEndpointOverride
+	}
+	if cfg.IdentityEndpointOverride != "" {
+		identityClient.Host = cfg.IdentityEndpointOverride
+	}
+
 	return &driverOCI{
-		computeClient: coreClient,
-		vcnClient:     vcnClient,
-		cfg:           cfg,
+		computeClient:      coreClient,
+		vcnClient:          vcnClient,
+		blockstorageClient: blockstorageClient,
+		identityClient:     identityClient,
+		cfg:                cfg,
+		ui:                 ui,
+		requestMetadata:    newRequestMetadata(*cfg.APIMaxRetries, cfg.APIRetryableStatusCodes),
 	}, nil
 }
 
+// setHTTPTimeout sets the timeout of the SDK's HTTP client, keeping the rest
+// of its settings. The SDK's own timeout is kept if timeout is not positive.
+func setHTTPTimeout(client *common.BaseClient, timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	if httpClient, ok := client.HTTPClient.(*http.Client); ok {
+		httpClient.Timeout = timeout
+	}
+}
+
 // CreateInstance creates a new compute instance.
 func (d *driverOCI) CreateInstance(ctx context.Context, publicKey string) (string, error) {
-	metadata := map[string]string{
-		"ssh_authorized_keys": publicKey,
-	}
-	if d.cfg.Metadata != nil {
-		for key, value := range d.cfg.Metadata {
-			metadata[key] = value
-		}
+	metadata := map[string]string{}
+	for key, value := range d.cfg.Metadata {
+		metadata[key] = value
 	}
 	if d.cfg.UserData != "" {
 		metadata["user_data"] = d.cfg.UserData
 	}
+	// The SSH communicator relies on this key so it always wins.
+	if publicKey != "" {
+		metadata[sshAuthorizedKeysMetadataKey] = publicKey
+	}
 
 	// Create VNIC details for instance
 	CreateVnicDetails := core.CreateVnicDetails{
@@ -95,54 +180,37 @@ func (d *driverOCI) CreateInstance(ctx context.Context, publicKey string) (strin
 		FreeformTags:        d.cfg.CreateVnicDetails.FreeformTags,
 	}
 
-	// Determine base image ID
-	var imageId *string
-	if d.cfg.BaseImageID != "" {
-		imageId = &d.cfg.BaseImageID
+	// Create Source details which will be used to Launch Instance
+	var InstanceSourceDetails core.InstanceSourceDetails
+	if d.cfg.SourceBootVolumeID != "" {
+		InstanceSourceDetails = core.InstanceSourceViaBootVolumeDetails{
+			BootVolumeId: &d.cfg.SourceBootVolumeID,
+		}
 	} else {
-		// Pull images and determine which image ID to use, if BaseImageId not specified
-		response, err := d.computeClient.ListImages(ctx, core.ListImagesRequest{
-			CompartmentId:          d.cfg.BaseImageFilter.CompartmentId,
-			DisplayName:            d.cfg.BaseImageFilter.DisplayName,
-			OperatingSystem:        d.cfg.BaseImageFilter.OperatingSystem,
-			OperatingSystemVersion: d.cfg.BaseImageFilter.OperatingSystemVersion,
-			Shape:                  d.cfg.BaseImageFilter.Shape,
-			LifecycleState:         "AVAILABLE",
-			SortBy:                 "TIMECREATED",
-			SortOrder:              "DESC",
-			RequestMetadata:        requestMetadata,
-		})
-		if err != nil {
-			return "", err
+		imageSourceDetails := core.InstanceSourceViaImageDetails{
+			ImageId: &d.cfg.BaseImageID,
 		}
-		if len(response.Items) == 0 {
-			return "", errors.New("base_image_filter returned no images")
+		if d.cfg.BootVolumeSizeInGBs != 0 {
+			imageSourceDetails.BootVolumeSizeInGBs = &d.cfg.BootVolumeSizeInGBs
 		}
-		if d.cfg.BaseImageFilter.DisplayNameSearch != nil {
-			// Return most recent image that matches regex
-			imageNameRegex, err := regexp.Compile(*d.cfg.BaseImageFilter.DisplayNameSearch)
-			if err != nil {
-				return "", err
-			}
-			for _, image := range response.Items {
-				if imageNameRegex.MatchString(*image.DisplayName) {
-					imageId = image.Id
-					break
-				}
-			}
-			if imageId == nil {
-				return "", errors.New("No image matched display_name_search criteria")
+		if d.cfg.KmsKeyID != "" {
+			imageSourceDetails.KmsKeyId = &d.cfg.KmsKeyID
+		}
+		InstanceSourceDetails = imageSourceDetails
+		if d.cfg.BootVolumeVpusPerGB != 0 {
+			InstanceSourceDetails = instanceSourceViaImageDetails{
+				InstanceSourceViaImageDetails: imageSourceDetails,
+				BootVolumeVpusPerGB:           &d.cfg.BootVolumeVpusPerGB,
 			}
-		} else {
-			// If no regex provided, simply return most recent image pulled
-			imageId = response.Items[0].Id
 		}
 	}
 
-	// Create Source details which will be used to Launch Instance
-	InstanceSourceDetails := core.InstanceSourceViaImageDetails{
-		ImageId:             imageId,
-		BootVolumeSizeInGBs: &d.cfg.BootVolumeSizeInGBs,
+	// Mark the build instance in the launch request itself, so that even an
+	// instance orphaned by a crashed build can be told apart from other
+	// workloads. A user supplied "Packer" tag takes precedence.
+	instanceTags := map[string]string{instancePackerTagKey: "true"}
+	for key, value := range d.cfg.InstanceTags {
+		instanceTags[key] = value
 	}
 
 	// Build instance details
@@ -152,16 +220,81 @@ func (d *driverOCI) CreateInstance(ctx context.Context, publicKey string) (strin
 		CreateVnicDetails:  &CreateVnicDetails,
 		DefinedTags:        d.cfg.InstanceDefinedTags,
 		DisplayName:        d.cfg.InstanceName,
-		FreeformTags:       d.cfg.InstanceTags,
+		FaultDomain:        d.cfg.FaultDomain,
+		FreeformTags:       instanceTags,
 		Shape:              &d.cfg.Shape,
 		SourceDetails:      InstanceSourceDetails,
 		Metadata:           metadata,
+		ExtendedMetadata:   d.cfg.ExtendedMetadata,
 	}
 
-	instance, err := d.computeClient.LaunchInstance(context.TODO(), core.LaunchInstanceRequest{
-		LaunchInstanceDetails: instanceDetails,
-		RequestMetadata:       requestMetadata,
-	})
+	if d.cfg.DedicatedVmHostID != "" {
+		instanceDetails.DedicatedVmHostId = &d.cfg.DedicatedVmHostID
+	}
+
+	if d.cfg.LaunchNetworkType != "" || d.cfg.LaunchBootVolumeType != "" || d.cfg.LaunchFirmware != "" {
+		instanceDetails.LaunchOptions = &core.LaunchOptions{
+			NetworkType:    core.LaunchOptionsNetworkTypeEnum(d.cfg.LaunchNetworkType),
+			BootVolumeType: core.LaunchOptionsBootVolumeTypeEnum(d.cfg.LaunchBootVolumeType),
+			Firmware:       core.LaunchOptionsFirmwareEnum(d.cfg.LaunchFirmware),
+		}
+	}
+
+	request := launchInstanceRequest{
+		LaunchInstanceRequest: core.LaunchInstanceRequest{
+			LaunchInstanceDetails: instanceDetails,
+			RequestMetadata:       d.requestMetadata,
+		},
+	}
+	if len(d.cfg.AgentDisabledPlugins) > 0 || d.cfg.AgentAreAllPluginsDisabled {
+		agentConfig := &launchInstanceAgentConfig{}
+		if d.cfg.AgentAreAllPluginsDisabled {
+			agentConfig.AreAllPluginsDisabled = &d.cfg.AgentAreAllPluginsDisabled
+		}
+		for _, plugin := range d.cfg.AgentDisabledPlugins {
+			agentConfig.PluginsConfig = append(agentConfig.PluginsConfig, instanceAgentPluginConfig{
+				Name:         plugin,
+				DesiredState: "DISABLED",
+			})
+		}
+		request.AgentConfig = agentConfig
+	}
+	if d.cfg.CapacityReservationID != "" {
+		request.CapacityReservationId = &d.cfg.CapacityReservationID
+	}
+	if d.cfg.ShapeOCPUs != 0 || d.cfg.ShapeMemoryInGBs != 0 {
+		request.ShapeConfig = &launchInstanceShapeConfig{}
+		if d.cfg.ShapeOCPUs != 0 {
+			request.ShapeConfig.Ocpus = &d.cfg.ShapeOCPUs
+		}
+		if d.cfg.ShapeMemoryInGBs != 0 {
+			request.ShapeConfig.MemoryInGBs = &d.cfg.ShapeMemoryInGBs
+		}
+	}
+	if d.cfg.DisableLegacyImdsEndpoints {
+		request.InstanceOptions = &instanceOptions{
+			AreLegacyImdsEndpointsDisabled: &d.cfg.DisableLegacyImdsEndpoints,
+		}
+	}
+	if d.cfg.SecureBoot || d.cfg.MeasuredBoot || d.cfg.TrustedPlatformModule {
+		request.PlatformConfig = &platformConfig{
+			Type:                           shieldedPlatformType(d.cfg.Shape),
+			IsSecureBootEnabled:            &d.cfg.SecureBoot,
+			IsMeasuredBootEnabled:          &d.cfg.MeasuredBoot,
+			IsTrustedPlatformModuleEnabled: &d.cfg.TrustedPlatformModule,
+		}
+	}
+	if d.cfg.Preemptible {
+		preserveBootVolume := false
+		request.PreemptibleInstanceConfig = &preemptibleInstanceConfig{
+			PreemptionAction: preemptionAction{
+				Type:               "TERMINATE",
+				PreserveBootVolume: &preserveBootVolume,
+			},
+		}
+	}
+
+	instance, err := launchInstance(ctx, d.computeClient, request)
 
 	if err != nil {
 		return "", err
@@ -170,153 +303,1299 @@ func (d *driverOCI) CreateInstance(ctx context.Context, publicKey string) (strin
 	return *instance.Id, nil
 }
 
-// CreateImage creates a new custom image.
-func (d *driverOCI) CreateImage(ctx context.Context, id string) (core.Image, error) {
-	res, err := d.computeClient.CreateImage(ctx, core.CreateImageRequest{CreateImageDetails: core.CreateImageDetails{
-		CompartmentId: &d.cfg.ImageCompartmentID,
-		InstanceId:    &id,
-		DisplayName:   &d.cfg.ImageName,
-		FreeformTags:  d.cfg.Tags,
-		DefinedTags:   d.cfg.DefinedTags,
-		LaunchMode:    core.CreateImageDetailsLaunchModeEnum(d.cfg.LaunchMode),
-	},
-		RequestMetadata: requestMetadata,
+// ListAvailabilityDomains returns the names of the availability domains of
+// the region that compartment_ocid can use.
+func (d *driverOCI) ListAvailabilityDomains(ctx context.Context) ([]string, error) {
+	response, err := d.identityClient.ListAvailabilityDomains(ctx, listAvailabilityDomainsRequest{
+		CompartmentId:   &d.cfg.IdentityCompartmentID,
+		RequestMetadata: d.requestMetadata,
 	})
-
 	if err != nil {
-		return core.Image{}, err
+		return nil, err
 	}
 
-	return res.Image, nil
+	var names []string
+	for _, ad := range response.Items {
+		names = append(names, *ad.Name)
+	}
+	return names, nil
 }
 
-// DeleteImage deletes a custom image.
-func (d *driverOCI) DeleteImage(ctx context.Context, id string) error {
-	_, err := d.computeClient.DeleteImage(ctx, core.DeleteImageRequest{
-		ImageId:         &id,
-		RequestMetadata: requestMetadata,
-	})
-	return err
+// ListImages returns the images matching the base image filter, most recently
+// created first.
+func (d *driverOCI) ListImages(ctx context.Context) ([]core.Image, error) {
+	var imageNameRegex *regexp.Regexp
+	if d.cfg.BaseImageFilter.DisplayNameSearch != nil {
+		var err error
+		imageNameRegex, err = regexp.Compile(*d.cfg.BaseImageFilter.DisplayNameSearch)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var images []core.Image
+	var page *string
+	for {
+		response, err := d.computeClient.ListImages(ctx, core.ListImagesRequest{
+			CompartmentId:          d.cfg.BaseImageFilter.CompartmentId,
+			DisplayName:            d.cfg.BaseImageFilter.DisplayName,
+			OperatingSystem:        d.cfg.BaseImageFilter.OperatingSystem,
+			OperatingSystemVersion: d.cfg.BaseImageFilter.OperatingSystemVersion,
+			Shape:                  d.cfg.BaseImageFilter.Shape,
+			LifecycleState:         "AVAILABLE",
+			SortBy:                 "TIMECREATED",
+			SortOrder:              "DESC",
+			Page:                   page,
+			RequestMetadata:        d.requestMetadata,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, image := range response.Items {
+			if imageNameRegex != nil && !imageNameRegex.MatchString(*image.DisplayName) {
+				continue
+			}
+			images = append(images, image)
+		}
+
+		if response.OpcNextPage == nil {
+			break
+		}
+		page = response.OpcNextPage
+	}
+
+	return images, nil
 }
 
-// GetInstanceIP returns the public or private IP corresponding to the given instance id.
-func (d *driverOCI) GetInstanceIP(ctx context.Context, id string) (string, error) {
-	vnics, err := d.computeClient.ListVnicAttachments(ctx, core.ListVnicAttachmentsRequest{
-		InstanceId:      &id,
-		CompartmentId:   &d.cfg.CompartmentID,
-		RequestMetadata: requestMetadata,
+// ListInstancesByTag returns the IDs of the instances in the compartment,
+// other than terminated ones, with the given freeform tag.
+func (d *driverOCI) ListInstancesByTag(ctx context.Context, tagKey, tagValue string) ([]string, error) {
+	var ids []string
+	var page *string
+	for {
+		response, err := d.computeClient.ListInstances(ctx, core.ListInstancesRequest{
+			CompartmentId:   &d.cfg.CompartmentID,
+			Page:            page,
+			RequestMetadata: d.requestMetadata,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, instance := range response.Items {
+			if instance.LifecycleState == core.InstanceLifecycleStateTerminated {
+				continue
+			}
+			if value, ok := instance.FreeformTags[tagKey]; ok && value == tagValue {
+				ids = append(ids, *instance.Id)
+			}
+		}
+
+		if response.OpcNextPage == nil {
+			break
+		}
+		page = response.OpcNextPage
+	}
+
+	return ids, nil
+}
+
+// ListShapes returns the names of the shapes available to the compartment in
+// the availability domain, limited to those compatible with imageID if it
+// isn't empty.
+func (d *driverOCI) ListShapes(ctx context.Context, imageID string) ([]string, error) {
+	var image *string
+	if imageID != "" {
+		image = &imageID
+	}
+
+	var shapes []string
+	var page *string
+	for {
+		response, err := d.computeClient.ListShapes(ctx, core.ListShapesRequest{
+			CompartmentId:      &d.cfg.CompartmentID,
+			AvailabilityDomain: &d.cfg.AvailabilityDomain,
+			ImageId:            image,
+			Page:               page,
+			RequestMetadata:    d.requestMetadata,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, shape := range response.Items {
+			shapes = append(shapes, *shape.Shape)
+		}
+
+		if response.OpcNextPage == nil {
+			break
+		}
+		page = response.OpcNextPage
+	}
+
+	return shapes, nil
+}
+
+// ListSubnets returns the available subnets of vcn_ocid named subnet_name
+// that instances in availability_domain can use.
+func (d *driverOCI) ListSubnets(ctx context.Context) ([]core.Subnet, error) {
+	var subnets []core.Subnet
+	var page *string
+	for {
+		response, err := d.vcnClient.ListSubnets(ctx, core.ListSubnetsRequest{
+			CompartmentId:   &d.cfg.NetworkCompartmentID,
+			VcnId:           &d.cfg.VcnID,
+			DisplayName:     &d.cfg.SubnetName,
+			LifecycleState:  core.SubnetLifecycleStateAvailable,
+			Page:            page,
+			RequestMetadata: d.requestMetadata,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, subnet := range response.Items {
+			// Regional subnets have no availability domain.
+			if subnet.AvailabilityDomain != nil && *subnet.AvailabilityDomain != d.cfg.AvailabilityDomain {
+				continue
+			}
+			subnets = append(subnets, subnet)
+		}
+
+		if response.OpcNextPage == nil {
+			break
+		}
+		page = response.OpcNextPage
+	}
+
+	return subnets, nil
+}
+
+// ListTagDefaults returns the values of the active tag defaults of a
+// compartment as defined tags. Tag defaults whose value must be given by the
+// user are left out.
+func (d *driverOCI) ListTagDefaults(ctx context.Context, compartmentID string) (map[string]map[string]interface{}, error) {
+	tags := map[string]map[string]interface{}{}
+	namespaces := map[string]string{}
+	var page *string
+	for {
+		response, err := d.identityClient.ListTagDefaults(ctx, listTagDefaultsRequest{
+			CompartmentId:   &compartmentID,
+			Page:            page,
+			RequestMetadata: d.requestMetadata,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tagDefault := range response.Items {
+			if tagDefault.LifecycleState != "ACTIVE" || tagDefault.Value == nil || *tagDefault.Value == "" {
+				continue
+			}
+
+			// Tag defaults only refer to their namespace by OCID.
+			namespace, ok := namespaces[*tagDefault.TagNamespaceId]
+			if !ok {
+				res, err := d.identityClient.GetTagNamespace(ctx, getTagNamespaceRequest{
+					TagNamespaceId:  tagDefault.TagNamespaceId,
+					RequestMetadata: d.requestMetadata,
+				})
+				if err != nil {
+					return nil, err
+				}
+				namespace = *res.TagNamespace.Name
+				namespaces[*tagDefault.TagNamespaceId] = namespace
+			}
+
+			if tags[namespace] == nil {
+				tags[namespace] = map[string]interface{}{}
+			}
+			tags[namespace][*tagDefault.TagDefinitionName] = *tagDefault.Value
+		}
+
+		if response.OpcNextPage == nil {
+			break
+		}
+		page = response.OpcNextPage
+	}
+
+	return tags, nil
+}
+
+// ListTagNamespaces returns the names of the active tag namespaces in
+// identity_compartment_ocid, by default the tenancy, and its subcompartments.
+func (d *driverOCI) ListTagNamespaces(ctx context.Context) ([]string, error) {
+	includeSubcompartments := true
+	var names []string
+	var page *string
+	for {
+		response, err := d.identityClient.ListTagNamespaces(ctx, listTagNamespacesRequest{
+			CompartmentId:          &d.cfg.IdentityCompartmentID,
+			IncludeSubcompartments: &includeSubcompartments,
+			Page:                   page,
+			RequestMetadata:        d.requestMetadata,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, namespace := range response.Items {
+			if namespace.LifecycleState == "ACTIVE" {
+				names = append(names, *namespace.Name)
+			}
+		}
+
+		if response.OpcNextPage == nil {
+			break
+		}
+		page = response.OpcNextPage
+	}
+
+	return names, nil
+}
+
+// AttachVolume attaches a block volume to an instance and waits for it to be
+// attached. It returns the OCID of the volume attachment.
+func (d *driverOCI) AttachVolume(ctx context.Context, instanceID, volumeID, attachmentType string) (string, error) {
+	var details core.AttachVolumeDetails = core.AttachParavirtualizedVolumeDetails{
+		InstanceId: &instanceID,
+		VolumeId:   &volumeID,
+	}
+	if attachmentType == "iscsi" {
+		details = core.AttachIScsiVolumeDetails{
+			InstanceId: &instanceID,
+			VolumeId:   &volumeID,
+		}
+	}
+
+	res, err := d.computeClient.AttachVolume(ctx, core.AttachVolumeRequest{
+		AttachVolumeDetails: details,
+		RequestMetadata:     d.requestMetadata,
 	})
 	if err != nil {
 		return "", err
 	}
 
-	if len(vnics.Items) == 0 {
-		return "", errors.New("instance has zero VNICs")
+	id := *res.VolumeAttachment.GetId()
+	return id, d.waitForVolumeAttachmentState(ctx, id, []string{"ATTACHING"}, "ATTACHED")
+}
+
+// AttachVnic attaches a secondary VNIC in the given subnet to an instance and
+// waits for it to be attached. It returns the OCID of the VNIC attachment.
+func (d *driverOCI) AttachVnic(ctx context.Context, instanceID, displayName, subnetID string, nsgIDs []string) (string, error) {
+	// Attaching fails with an opaque error when an AD-specific subnet is in
+	// another availability domain.
+	subnet, err := d.GetSubnet(ctx, subnetID)
+	if err != nil {
+		return "", err
+	}
+	if ad := subnet.AvailabilityDomain; ad != nil && *ad != "" && *ad != d.cfg.AvailabilityDomain {
+		return "", fmt.Errorf("subnet %s is in availability domain %q but the instance is in %q",
+			subnetID, *ad, d.cfg.AvailabilityDomain)
 	}
 
-	vnic, err := d.vcnClient.GetVnic(ctx, core.GetVnicRequest{
-		VnicId:          vnics.Items[0].VnicId,
-		RequestMetadata: requestMetadata,
+	res, err := d.computeClient.AttachVnic(ctx, core.AttachVnicRequest{
+		AttachVnicDetails: core.AttachVnicDetails{
+			CreateVnicDetails: &core.CreateVnicDetails{
+				SubnetId:    &subnetID,
+				DisplayName: &displayName,
+				NsgIds:      nsgIDs,
+			},
+			InstanceId:  &instanceID,
+			DisplayName: &displayName,
+		},
+		RequestMetadata: d.requestMetadata,
 	})
 	if err != nil {
-		return "", fmt.Errorf("Error getting VNIC details: %s", err)
+		return "", err
 	}
 
-	if d.cfg.UsePrivateIP {
-		return *vnic.PrivateIp, nil
+	id := *res.Id
+	return id, d.waitForVnicAttachmentState(ctx, id, []string{"ATTACHING"}, "ATTACHED")
+}
+
+// ChangeImageCompartment moves a custom image to another compartment.
+func (d *driverOCI) ChangeImageCompartment(ctx context.Context, id, compartmentID string) error {
+	_, err := d.computeClient.ChangeImageCompartment(ctx, core.ChangeImageCompartmentRequest{
+		ImageId: &id,
+		ChangeImageCompartmentDetails: core.ChangeImageCompartmentDetails{
+			CompartmentId: &compartmentID,
+		},
+		RequestMetadata: d.requestMetadata,
+	})
+	return err
+}
+
+// CopyImageCapabilitySchema gives a custom image the capabilities of an
+// existing image capability schema, by creating a schema for the image with
+// the same schema version and data. It returns the OCID of the new schema.
+func (d *driverOCI) CopyImageCapabilitySchema(ctx context.Context, schemaID, imageID string) (string, error) {
+	schema, err := d.computeClient.GetComputeImageCapabilitySchema(ctx, core.GetComputeImageCapabilitySchemaRequest{
+		ComputeImageCapabilitySchemaId: &schemaID,
+		RequestMetadata:                d.requestMetadata,
+	})
+	if err != nil {
+		return "", err
 	}
 
-	if vnic.PublicIp == nil {
-		return "", fmt.Errorf("Error getting VNIC Public Ip for: %s", id)
+	res, err := d.computeClient.CreateComputeImageCapabilitySchema(ctx, core.CreateComputeImageCapabilitySchemaRequest{
+		CreateComputeImageCapabilitySchemaDetails: core.CreateComputeImageCapabilitySchemaDetails{
+			CompartmentId: &d.cfg.ImageCompartmentID,
+			ComputeGlobalImageCapabilitySchemaVersionName: schema.ComputeGlobalImageCapabilitySchemaVersionName,
+			DisplayName: &d.cfg.ImageName,
+			ImageId:     &imageID,
+			SchemaData:  schema.SchemaData,
+		},
+		RequestMetadata: d.requestMetadata,
+	})
+	if err != nil {
+		return "", err
 	}
 
-	return *vnic.PublicIp, nil
+	return *res.Id, nil
 }
 
-func (d *driverOCI) GetInstanceInitialCredentials(ctx context.Context, id string) (string, string, error) {
-	credentials, err := d.computeClient.GetWindowsInstanceInitialCredentials(ctx, core.GetWindowsInstanceInitialCredentialsRequest{
-		InstanceId:      &id,
-		RequestMetadata: requestMetadata,
+// CreateImage creates a new custom image in image_compartment_ocid.
+func (d *driverOCI) CreateImage(ctx context.Context, id string) (core.Image, error) {
+	tags := d.cfg.ImageTags
+	// BaseImageID is only known here once a filter or import has been
+	// resolved. A user supplied "base_image" tag takes precedence.
+	if d.cfg.TagBaseImage && d.cfg.BaseImageID != "" {
+		tags = map[string]string{imageBaseImageTagKey: d.cfg.BaseImageID}
+		for key, value := range d.cfg.ImageTags {
+			tags[key] = value
+		}
+	}
+
+	res, err := d.computeClient.CreateImage(ctx, core.CreateImageRequest{CreateImageDetails: core.CreateImageDetails{
+		CompartmentId: &d.cfg.ImageCompartmentID,
+		InstanceId:    &id,
+		DisplayName:   &d.cfg.ImageName,
+		FreeformTags:  tags,
+		DefinedTags:   d.cfg.ImageDefinedTags,
+		LaunchMode:    core.CreateImageDetailsLaunchModeEnum(d.cfg.LaunchMode),
+	},
+		RequestMetadata: d.requestMetadata,
 	})
+
 	if err != nil {
-		return "", "", err
+		return core.Image{}, err
 	}
 
-	return *credentials.InstanceCredentials.Username, *credentials.InstanceCredentials.Password, err
+	return res.Image, nil
 }
 
-// TerminateInstance terminates a compute instance.
-func (d *driverOCI) TerminateInstance(ctx context.Context, id string) error {
-	_, err := d.computeClient.TerminateInstance(ctx, core.TerminateInstanceRequest{
-		InstanceId:      &id,
-		RequestMetadata: requestMetadata,
+// CreateVolume creates a block volume in the instance's availability domain
+// and waits for it to become available. It returns the OCID of the volume.
+// The volume is always tagged Packer=true unless freeformTags overrides it.
+func (d *driverOCI) CreateVolume(ctx context.Context, displayName string, sizeInGBs int64, freeformTags map[string]string, definedTags map[string]map[string]interface{}) (string, error) {
+	tags := map[string]string{instancePackerTagKey: "true"}
+	for k, v := range freeformTags {
+		tags[k] = v
+	}
+
+	res, err := d.blockstorageClient.CreateVolume(ctx, core.CreateVolumeRequest{
+		CreateVolumeDetails: core.CreateVolumeDetails{
+			AvailabilityDomain: &d.cfg.AvailabilityDomain,
+			CompartmentId:      &d.cfg.CompartmentID,
+			DisplayName:        &displayName,
+			SizeInGBs:          &sizeInGBs,
+			FreeformTags:       tags,
+			DefinedTags:        definedTags,
+		},
+		RequestMetadata: d.requestMetadata,
 	})
-	return err
-}
+	if err != nil {
+		return "", err
+	}
 
-// WaitForImageCreation waits for a provisioning custom image to reach the
-// "AVAILABLE" state.
-func (d *driverOCI) WaitForImageCreation(ctx context.Context, id string) error {
-	return waitForResourceToReachState(
+	id := *res.Id
+	return id, waitForResourceToReachState(
+		ctx,
 		func(string) (string, error) {
-			image, err := d.computeClient.GetImage(ctx, core.GetImageRequest{
-				ImageId:         &id,
-				RequestMetadata: requestMetadata,
+			volume, err := d.blockstorageClient.GetVolume(ctx, core.GetVolumeRequest{
+				VolumeId:        &id,
+				RequestMetadata: d.requestMetadata,
 			})
 			if err != nil {
 				return "", err
 			}
-			return string(image.LifecycleState), nil
+			return string(volume.LifecycleState), nil
 		},
 		id,
-		[]string{"PROVISIONING"},
+		[]string{"PROVISIONING", "RESTORING"},
 		"AVAILABLE",
-		0,             //Unlimited Retries
-		5*time.Second, //5 second wait between retries
+		d.cfg.StateTimeout,
+		d.cfg.pollBackoff(),
+		d.reportState("volume"),
 	)
 }
 
-// WaitForInstanceState waits for an instance to reach the a given terminal
-// state.
-func (d *driverOCI) WaitForInstanceState(ctx context.Context, id string, waitStates []string, terminalState string) error {
-	return waitForResourceToReachState(
-		func(string) (string, error) {
-			instance, err := d.computeClient.GetInstance(ctx, core.GetInstanceRequest{
-				InstanceId:      &id,
-				RequestMetadata: requestMetadata,
-			})
-			if err != nil {
+// ImportImage starts importing the base image from Object Storage. The
+// image is IMPORTING until WaitForImageCreation returns.
+func (d *driverOCI) ImportImage(ctx context.Context) (core.Image, error) {
+	res, err := d.computeClient.CreateImage(ctx, core.CreateImageRequest{
+		CreateImageDetails: core.CreateImageDetails{
+			CompartmentId: &d.cfg.CompartmentID,
+			DisplayName:   &d.cfg.BaseImageImportName,
+			FreeformTags:  map[string]string{instancePackerTagKey: "true"},
+			ImageSourceDetails: core.ImageSourceViaObjectStorageTupleDetails{
+				BucketName:      &d.cfg.BaseImageImportBucket,
+				NamespaceName:   &d.cfg.BaseImageImportNamespace,
+				ObjectName:      &d.cfg.BaseImageImportName,
+				SourceImageType: core.ImageSourceDetailsSourceImageTypeEnum(d.cfg.BaseImageImportFormat),
+			},
+		},
+		RequestMetadata: d.requestMetadata,
+	})
+	if err != nil {
+		return core.Image{}, err
+	}
+
+	return res.Image, nil
+}
+
+// RemovePublicIP unassigns and deletes the ephemeral public IP of an
+// instance's primary VNIC, waiting for it to be terminated. An instance
+// without one is left as it is.
+func (d *driverOCI) RemovePublicIP(ctx context.Context, instanceID string) error {
+	attachments, err := d.waitForVnicAttachments(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+
+	var vnic core.Vnic
+	for _, attachment := range attachments {
+		vnic, err = d.getVnic(ctx, attachment.VnicId)
+		if err != nil {
+			return err
+		}
+		if vnic.IsPrimary != nil && *vnic.IsPrimary {
+			break
+		}
+		vnic = core.Vnic{}
+	}
+	if vnic.PublicIp == nil || *vnic.PublicIp == "" {
+		return nil
+	}
+
+	publicIP, err := d.vcnClient.GetPublicIpByIpAddress(ctx, core.GetPublicIpByIpAddressRequest{
+		GetPublicIpByIpAddressDetails: core.GetPublicIpByIpAddressDetails{IpAddress: vnic.PublicIp},
+		RequestMetadata:               d.requestMetadata,
+	})
+	if err != nil {
+		return err
+	}
+	// Deleting a reserved public IP would release it for good.
+	if publicIP.Lifetime != core.PublicIpLifetimeEphemeral {
+		return fmt.Errorf("public IP %s of instance %s is %s rather than EPHEMERAL",
+			*vnic.PublicIp, instanceID, publicIP.Lifetime)
+	}
+
+	id := *publicIP.Id
+	_, err = d.vcnClient.DeletePublicIp(ctx, core.DeletePublicIpRequest{
+		PublicIpId:      &id,
+		RequestMetadata: d.requestMetadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	return waitForResourceToReachState(
+		ctx,
+		func(string) (string, error) {
+			res, err := d.vcnClient.GetPublicIp(ctx, core.GetPublicIpRequest{
+				PublicIpId:      &id,
+				RequestMetadata: d.requestMetadata,
+			})
+			if isNotFound(err) {
+				return string(core.PublicIpLifecycleStateTerminated), nil
+			}
+			if err != nil {
+				return "", err
+			}
+			return string(res.LifecycleState), nil
+		},
+		id,
+		[]string{"ASSIGNED", "UNASSIGNING", "UNASSIGNED", "TERMINATING"},
+		"TERMINATED",
+		d.cfg.StateTimeout,
+		d.cfg.pollBackoff(),
+		d.reportState("public IP"),
+	)
+}
+
+// DeleteImage deletes a custom image. An image that no longer exists is
+// treated as deleted.
+func (d *driverOCI) DeleteImage(ctx context.Context, id string) error {
+	_, err := d.computeClient.DeleteImage(ctx, core.DeleteImageRequest{
+		ImageId:         &id,
+		RequestMetadata: d.requestMetadata,
+	})
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// DeleteVolume deletes a block volume. A volume that no longer exists is
+// treated as deleted.
+func (d *driverOCI) DeleteVolume(ctx context.Context, id string) error {
+	_, err := d.blockstorageClient.DeleteVolume(ctx, core.DeleteVolumeRequest{
+		VolumeId:        &id,
+		RequestMetadata: d.requestMetadata,
+	})
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// DetachVolume detaches a block volume from an instance and waits for it to
+// be detached.
+func (d *driverOCI) DetachVolume(ctx context.Context, attachmentID string) error {
+	_, err := d.computeClient.DetachVolume(ctx, core.DetachVolumeRequest{
+		VolumeAttachmentId: &attachmentID,
+		RequestMetadata:    d.requestMetadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	return d.waitForVolumeAttachmentState(ctx, attachmentID, []string{"DETACHING"}, "DETACHED")
+}
+
+// DetachVnic detaches a secondary VNIC, which deletes it, and waits for it to
+// be detached.
+func (d *driverOCI) DetachVnic(ctx context.Context, attachmentID string) error {
+	_, err := d.computeClient.DetachVnic(ctx, core.DetachVnicRequest{
+		VnicAttachmentId: &attachmentID,
+		RequestMetadata:  d.requestMetadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	return d.waitForVnicAttachmentState(ctx, attachmentID, []string{"DETACHING"}, "DETACHED")
+}
+
+// exportImageViaObjectStorageTupleDetails extends
+// core.ExportImageViaObjectStorageTupleDetails with the export format, which
+// the vendored SDK predates. OCI exports in its own format when it is empty.
+type exportImageViaObjectStorageTupleDetails struct {
+	core.ExportImageViaObjectStorageTupleDetails
+
+	ExportFormat string
+}
+
+func (m exportImageViaObjectStorageTupleDetails) MarshalJSON() ([]byte, error) {
+	if m.ExportFormat == "" {
+		return json.Marshal(m.ExportImageViaObjectStorageTupleDetails)
+	}
+	return marshalWithFields(m.ExportImageViaObjectStorageTupleDetails, map[string]interface{}{
+		"exportFormat": m.ExportFormat,
+	})
+}
+
+// ExportImage exports a custom image to Object Storage and waits for the
+// export to finish. It returns the URI of the exported object.
+func (d *driverOCI) ExportImage(ctx context.Context, id string) (string, error) {
+	_, err := d.computeClient.ExportImage(ctx, core.ExportImageRequest{
+		ImageId: &id,
+		ExportImageDetails: exportImageViaObjectStorageTupleDetails{
+			ExportImageViaObjectStorageTupleDetails: core.ExportImageViaObjectStorageTupleDetails{
+				BucketName:    &d.cfg.ImageExportBucket,
+				NamespaceName: &d.cfg.ImageExportNamespace,
+				ObjectName:    &d.cfg.ImageExportName,
+			},
+			ExportFormat: strings.ToUpper(d.cfg.ImageExportFormat),
+		},
+		RequestMetadata: d.requestMetadata,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = waitForResourceToReachState(
+		ctx,
+		func(string) (string, error) {
+			image, err := d.GetImage(ctx, id)
+			if err != nil {
+				return "", err
+			}
+			return string(image.LifecycleState), nil
+		},
+		id,
+		[]string{"EXPORTING"},
+		"AVAILABLE",
+		d.cfg.StateTimeout,
+		d.cfg.pollBackoff(),
+		d.reportState("image"),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	region, err := d.cfg.configProvider.Region()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("https://%s/n/%s/b/%s/o/%s",
+		common.StringToRegion(region).Endpoint("objectstorage"),
+		d.cfg.ImageExportNamespace, d.cfg.ImageExportBucket, url.PathEscape(d.cfg.ImageExportName)), nil
+}
+
+// GetImage returns the custom image with the given id.
+func (d *driverOCI) GetImage(ctx context.Context, id string) (core.Image, error) {
+	res, err := d.computeClient.GetImage(ctx, core.GetImageRequest{
+		ImageId:         &id,
+		RequestMetadata: d.requestMetadata,
+	})
+	if err != nil {
+		return core.Image{}, err
+	}
+
+	return res.Image, nil
+}
+
+// GetInstanceIP returns the public or private IP corresponding to the given instance id.
+func (d *driverOCI) GetInstanceIP(ctx context.Context, id string) (string, error) {
+	vnic, err := d.getCommVnic(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	if d.cfg.IPVersion == ipVersionIPv6 {
+		return d.getVnicIpv6(ctx, id, vnic)
+	}
+
+	kind, address := "public", func(vnic core.Vnic) *string { return vnic.PublicIp }
+	if d.cfg.UsePrivateIP {
+		kind, address = "private", func(vnic core.Vnic) *string { return vnic.PrivateIp }
+	}
+
+	// Right after a VNIC is attached its IP can still be empty, which would
+	// leave the communicator without a host, so poll until it is assigned.
+	var ip string
+	vnicID := vnic.Id
+	refresh := false
+	err = waitForResourceToReachState(
+		ctx,
+		func(string) (string, error) {
+			if refresh {
+				if vnic, err = d.getVnic(ctx, vnicID); err != nil {
+					return "", err
+				}
+			}
+			refresh = true
+			if a := address(vnic); a != nil && *a != "" {
+				ip = *a
+				return "ASSIGNED", nil
+			}
+			return "UNASSIGNED", nil
+		},
+		id,
+		[]string{"UNASSIGNED"},
+		"ASSIGNED",
+		d.vnicTimeout(),
+		d.cfg.pollBackoff(),
+		nil,
+	)
+	if err != nil {
+		return "", fmt.Errorf("instance %s has no %s IP on VNIC %s: %s", id, kind, *vnicID, err)
+	}
+	return ip, nil
+}
+
+// getVnicIpv6 returns the first available IPv6 address of an instance's VNIC.
+// IPv6 addresses are reachable from the internet when the subnet allows it,
+// so there is no public and private address to choose between. Like the IPv4
+// addresses, it polls until the address is assigned.
+func (d *driverOCI) getVnicIpv6(ctx context.Context, id string, vnic core.Vnic) (string, error) {
+	var ip string
+	checkedSubnet := false
+	err := waitForResourceToReachState(
+		ctx,
+		func(string) (string, error) {
+			var err error
+			if ip, err = d.listVnicIpv6(ctx, vnic.Id); err != nil {
+				return "", err
+			}
+			if ip != "" {
+				return "ASSIGNED", nil
+			}
+			// A subnet without IPv6 never assigns an address, so don't
+			// wait for one.
+			if !checkedSubnet {
+				checkedSubnet = true
+				subnet, err := d.GetSubnet(ctx, *vnic.SubnetId)
+				if err == nil && subnet.Ipv6CidrBlock == nil {
+					return "", fmt.Errorf("its subnet %s does not support IPv6", *vnic.SubnetId)
+				}
+			}
+			return "UNASSIGNED", nil
+		},
+		id,
+		[]string{"UNASSIGNED"},
+		"ASSIGNED",
+		d.vnicTimeout(),
+		d.cfg.pollBackoff(),
+		nil,
+	)
+	if err != nil {
+		return "", fmt.Errorf("instance %s has no IPv6 address assigned to VNIC %s: %s", id, *vnic.Id, err)
+	}
+	return ip, nil
+}
+
+// listVnicIpv6 returns the first available IPv6 address of a VNIC, or an
+// empty string if none is available yet.
+func (d *driverOCI) listVnicIpv6(ctx context.Context, vnicID *string) (string, error) {
+	request := core.ListIpv6sRequest{
+		VnicId:          vnicID,
+		RequestMetadata: d.requestMetadata,
+	}
+	for {
+		resp, err := d.vcnClient.ListIpv6s(ctx, request)
+		if err != nil {
+			return "", fmt.Errorf("Error listing IPv6 addresses of VNIC %s: %s", *vnicID, err)
+		}
+		for _, ipv6 := range resp.Items {
+			if ipv6.LifecycleState == core.Ipv6LifecycleStateAvailable && ipv6.IpAddress != nil {
+				return *ipv6.IpAddress, nil
+			}
+		}
+		if resp.OpcNextPage == nil {
+			return "", nil
+		}
+		request.Page = resp.OpcNextPage
+	}
+}
+
+// getCommVnic returns the VNIC of an instance to communicate with: the one
+// selected by comm_vnic_index or comm_vnic_name, or else the primary VNIC.
+// The order of the VNIC attachments isn't guaranteed so each attached VNIC is
+// checked.
+func (d *driverOCI) getCommVnic(ctx context.Context, id string) (core.Vnic, error) {
+	attachments, err := d.waitForVnicAttachments(ctx, id)
+	if err != nil {
+		return core.Vnic{}, err
+	}
+
+	if d.cfg.CommVnicIndex != nil {
+		// Attachments are indexed in the order they were attached.
+		sort.SliceStable(attachments, func(i, j int) bool {
+			return attachments[i].TimeCreated.Before(attachments[j].TimeCreated.Time)
+		})
+		if *d.cfg.CommVnicIndex >= len(attachments) {
+			return core.Vnic{}, fmt.Errorf("instance %s has no VNIC with index %d, it has %d VNICs",
+				id, *d.cfg.CommVnicIndex, len(attachments))
+		}
+		return d.getVnic(ctx, attachments[*d.cfg.CommVnicIndex].VnicId)
+	}
+
+	for _, attachment := range attachments {
+		vnic, err := d.getVnic(ctx, attachment.VnicId)
+		if err != nil {
+			return core.Vnic{}, err
+		}
+
+		if d.cfg.CommVnicName != "" {
+			if vnic.DisplayName != nil && *vnic.DisplayName == d.cfg.CommVnicName {
+				return vnic, nil
+			}
+		} else if vnic.IsPrimary != nil && *vnic.IsPrimary {
+			return vnic, nil
+		}
+	}
+
+	if d.cfg.CommVnicName != "" {
+		return core.Vnic{}, fmt.Errorf("instance %s has no attached VNIC named %q", id, d.cfg.CommVnicName)
+	}
+	return core.Vnic{}, fmt.Errorf("instance %s has no attached primary VNIC", id)
+}
+
+// vnicWaitTimeout bounds the wait for a RUNNING instance to have an attached
+// VNIC with an IP, which OCI can take a few seconds to list.
+const vnicWaitTimeout = 2 * time.Minute
+
+// vnicTimeout returns how long to wait for an instance's VNIC, which is
+// vnicWaitTimeout unless state_timeout is shorter.
+func (d *driverOCI) vnicTimeout() time.Duration {
+	if d.cfg.StateTimeout > 0 && d.cfg.StateTimeout < vnicWaitTimeout {
+		return d.cfg.StateTimeout
+	}
+	return vnicWaitTimeout
+}
+
+// waitForVnicAttachments returns the attached VNIC attachments of an
+// instance, polling until there is at least one.
+func (d *driverOCI) waitForVnicAttachments(ctx context.Context, id string) ([]core.VnicAttachment, error) {
+	var attachments []core.VnicAttachment
+	err := waitForResourceToReachState(
+		ctx,
+		func(string) (string, error) {
+			var err error
+			attachments, err = d.listAttachedVnicAttachments(ctx, id)
+			if err != nil {
+				return "", err
+			}
+			if len(attachments) == 0 {
+				return "NO_VNICS", nil
+			}
+			return "ATTACHED", nil
+		},
+		id,
+		[]string{"NO_VNICS"},
+		"ATTACHED",
+		d.vnicTimeout(),
+		d.cfg.pollBackoff(),
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("instance %s has no attached VNICs: %s", id, err)
+	}
+	return attachments, nil
+}
+
+// listAttachedVnicAttachments returns every VNIC attachment of an instance
+// that is attached.
+func (d *driverOCI) listAttachedVnicAttachments(ctx context.Context, id string) ([]core.VnicAttachment, error) {
+	var attached []core.VnicAttachment
+	var page *string
+	for {
+		attachments, err := d.computeClient.ListVnicAttachments(ctx, core.ListVnicAttachmentsRequest{
+			InstanceId:      &id,
+			CompartmentId:   &d.cfg.NetworkCompartmentID,
+			Page:            page,
+			RequestMetadata: d.requestMetadata,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, attachment := range attachments.Items {
+			if attachment.LifecycleState == core.VnicAttachmentLifecycleStateAttached {
+				attached = append(attached, attachment)
+			}
+		}
+
+		if attachments.OpcNextPage == nil {
+			break
+		}
+		page = attachments.OpcNextPage
+	}
+
+	return attached, nil
+}
+
+func (d *driverOCI) getVnic(ctx context.Context, id *string) (core.Vnic, error) {
+	vnic, err := d.vcnClient.GetVnic(ctx, core.GetVnicRequest{
+		VnicId:          id,
+		RequestMetadata: d.requestMetadata,
+	})
+	if err != nil {
+		return core.Vnic{}, fmt.Errorf("Error getting VNIC details: %s", err)
+	}
+	return vnic.Vnic, nil
+}
+
+// GetDedicatedVmHost gets a dedicated virtual machine host.
+func (d *driverOCI) GetDedicatedVmHost(ctx context.Context, id string) (core.DedicatedVmHost, error) {
+	res, err := d.computeClient.GetDedicatedVmHost(ctx, core.GetDedicatedVmHostRequest{
+		DedicatedVmHostId: &id,
+		RequestMetadata:   d.requestMetadata,
+	})
+	return res.DedicatedVmHost, err
+}
+
+// GetSubnet gets a subnet.
+func (d *driverOCI) GetSubnet(ctx context.Context, id string) (core.Subnet, error) {
+	res, err := d.vcnClient.GetSubnet(ctx, core.GetSubnetRequest{
+		SubnetId:        &id,
+		RequestMetadata: d.requestMetadata,
+	})
+	return res.Subnet, err
+}
+
+// GetMarketplaceListingVersion gets the version of the Marketplace listing
+// to launch the instance from: marketplace_package_version, or else the most
+// recently published version.
+func (d *driverOCI) GetMarketplaceListingVersion(ctx context.Context) (core.AppCatalogListingResourceVersion, error) {
+	version := d.cfg.MarketplacePackageVersion
+	if version == "" {
+		versions, err := d.computeClient.ListAppCatalogListingResourceVersions(ctx, core.ListAppCatalogListingResourceVersionsRequest{
+			ListingId:       &d.cfg.MarketplaceListingID,
+			SortOrder:       core.ListAppCatalogListingResourceVersionsSortOrderDesc,
+			RequestMetadata: d.requestMetadata,
+		})
+		if err != nil {
+			return core.AppCatalogListingResourceVersion{}, err
+		}
+		if len(versions.Items) == 0 || versions.Items[0].ListingResourceVersion == nil {
+			return core.AppCatalogListingResourceVersion{}, fmt.Errorf("listing %s has no published versions", d.cfg.MarketplaceListingID)
+		}
+		version = *versions.Items[0].ListingResourceVersion
+	}
+
+	res, err := d.computeClient.GetAppCatalogListingResourceVersion(ctx, core.GetAppCatalogListingResourceVersionRequest{
+		ListingId:       &d.cfg.MarketplaceListingID,
+		ResourceVersion: &version,
+		RequestMetadata: d.requestMetadata,
+	})
+	return res.AppCatalogListingResourceVersion, err
+}
+
+// SubscribeMarketplaceListing accepts the terms of a version of the
+// Marketplace listing on behalf of the compartment, which OCI requires
+// before instances can be launched from its image.
+func (d *driverOCI) SubscribeMarketplaceListing(ctx context.Context, version string) error {
+	agreements, err := d.computeClient.GetAppCatalogListingAgreements(ctx, core.GetAppCatalogListingAgreementsRequest{
+		ListingId:       &d.cfg.MarketplaceListingID,
+		ResourceVersion: &version,
+		RequestMetadata: d.requestMetadata,
+	})
+	if err != nil {
+		return fmt.Errorf("Error getting the listing's agreements: %s", err)
+	}
+
+	_, err = d.computeClient.CreateAppCatalogSubscription(ctx, core.CreateAppCatalogSubscriptionRequest{
+		CreateAppCatalogSubscriptionDetails: core.CreateAppCatalogSubscriptionDetails{
+			CompartmentId:          &d.cfg.CompartmentID,
+			ListingId:              &d.cfg.MarketplaceListingID,
+			ListingResourceVersion: &version,
+			OracleTermsOfUseLink:   agreements.OracleTermsOfUseLink,
+			EulaLink:               agreements.EulaLink,
+			TimeRetrieved:          agreements.TimeRetrieved,
+			Signature:              agreements.Signature,
+		},
+		RequestMetadata: d.requestMetadata,
+	})
+	return err
+}
+
+// GetInstanceInitialCredentials returns the initial username and password of
+// a Windows instance.
+func (d *driverOCI) GetInstanceInitialCredentials(ctx context.Context, id string) (string, string, error) {
+	credentials, err := d.computeClient.GetWindowsInstanceInitialCredentials(ctx, core.GetWindowsInstanceInitialCredentialsRequest{
+		InstanceId:      &id,
+		RequestMetadata: d.requestMetadata,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return *credentials.InstanceCredentials.Username, *credentials.InstanceCredentials.Password, err
+}
+
+// TerminateInstance terminates a compute instance. An instance that no
+// longer exists is treated as terminated.
+func (d *driverOCI) TerminateInstance(ctx context.Context, id string) error {
+	// A boot volume the instance was launched from belongs to the user so
+	// must outlive the instance.
+	preserveBootVolume := d.cfg.SourceBootVolumeID != ""
+	_, err := d.computeClient.TerminateInstance(ctx, core.TerminateInstanceRequest{
+		InstanceId:         &id,
+		PreserveBootVolume: &preserveBootVolume,
+		RequestMetadata:    d.requestMetadata,
+	})
+	if isNotFound(err) {
+		return nil
+	} else if err != nil {
+		// A preempted instance may already have been terminated by OCI.
+		instance, getErr := d.computeClient.GetInstance(ctx, core.GetInstanceRequest{
+			InstanceId:      &id,
+			RequestMetadata: d.requestMetadata,
+		})
+		if getErr == nil && instance.LifecycleState == core.InstanceLifecycleStateTerminated {
+			return nil
+		}
+	}
+	return err
+}
+
+// SetImageOperatingSystem sets the OS and OS version of a custom image. They
+// can't be given when the image is created from an instance.
+func (d *driverOCI) SetImageOperatingSystem(ctx context.Context, id, operatingSystem, version string) error {
+	_, err := d.computeClient.UpdateImage(ctx, core.UpdateImageRequest{
+		ImageId: &id,
+		UpdateImageDetails: core.UpdateImageDetails{
+			OperatingSystem:        &operatingSystem,
+			OperatingSystemVersion: &version,
+		},
+		RequestMetadata: d.requestMetadata,
+	})
+	return err
+}
+
+// StopInstance gracefully shuts down an instance. It doesn't wait for the
+// instance to be STOPPED.
+func (d *driverOCI) StopInstance(ctx context.Context, id string) error {
+	_, err := d.computeClient.InstanceAction(ctx, core.InstanceActionRequest{
+		InstanceId:      &id,
+		Action:          core.InstanceActionActionSoftstop,
+		RequestMetadata: d.requestMetadata,
+	})
+	return err
+}
+
+// UpdateImage merges the given freeform and defined tags into those of a
+// custom image, keeping any tags of the image that aren't given.
+func (d *driverOCI) UpdateImage(ctx context.Context, id string, freeformTags map[string]string, definedTags map[string]map[string]interface{}) (core.Image, error) {
+	current, err := d.computeClient.GetImage(ctx, core.GetImageRequest{
+		ImageId:         &id,
+		RequestMetadata: d.requestMetadata,
+	})
+	if err != nil {
+		return core.Image{}, err
+	}
+
+	mergedFreeformTags := map[string]string{}
+	for key, value := range current.FreeformTags {
+		mergedFreeformTags[key] = value
+	}
+	for key, value := range freeformTags {
+		mergedFreeformTags[key] = value
+	}
+
+	mergedDefinedTags := map[string]map[string]interface{}{}
+	for _, tags := range []map[string]map[string]interface{}{current.DefinedTags, definedTags} {
+		for namespace, values := range tags {
+			if mergedDefinedTags[namespace] == nil {
+				mergedDefinedTags[namespace] = map[string]interface{}{}
+			}
+			for key, value := range values {
+				mergedDefinedTags[namespace][key] = value
+			}
+		}
+	}
+
+	// Only update the image as it was read so that concurrent tag changes
+	// aren't lost.
+	res, err := d.computeClient.UpdateImage(ctx, core.UpdateImageRequest{
+		ImageId: &id,
+		UpdateImageDetails: core.UpdateImageDetails{
+			FreeformTags: mergedFreeformTags,
+			DefinedTags:  mergedDefinedTags,
+		},
+		IfMatch:         current.Etag,
+		RequestMetadata: d.requestMetadata,
+	})
+	if err != nil {
+		return core.Image{}, err
+	}
+
+	return res.Image, nil
+}
+
+// WaitForImageCreation waits for a provisioning or importing custom image to
+// reach the "AVAILABLE" state.
+func (d *driverOCI) WaitForImageCreation(ctx context.Context, id string) error {
+	return waitForResourceToReachState(
+		ctx,
+		func(string) (string, error) {
+			image, err := d.GetImage(ctx, id)
+			if err != nil {
+				return "", err
+			}
+			return string(image.LifecycleState), nil
+		},
+		id,
+		[]string{"PROVISIONING", "IMPORTING"},
+		"AVAILABLE",
+		d.cfg.ImageCreateTimeout,
+		d.cfg.pollBackoff(),
+		d.reportState("image"),
+	)
+}
+
+// WaitForInstanceState waits for an instance to reach one of the given
+// terminal states, returning the state it settled in. Waiting for it to be
+// RUNNING is bounded by instance_launch_timeout.
+func (d *driverOCI) WaitForInstanceState(ctx context.Context, id string, waitStates, terminalStates []string) (string, error) {
+	timeout := d.cfg.StateTimeout
+	if stringSliceContains(terminalStates, "RUNNING") {
+		timeout = d.cfg.InstanceLaunchTimeout
+	}
+
+	return waitForResourceToReachStates(
+		ctx,
+		func(string) (string, error) {
+			instance, err := d.computeClient.GetInstance(ctx, core.GetInstanceRequest{
+				InstanceId:      &id,
+				RequestMetadata: d.requestMetadata,
+			})
+			if err != nil {
 				return "", err
 			}
 			return string(instance.LifecycleState), nil
 		},
 		id,
 		waitStates,
+		terminalStates,
+		timeout,
+		d.cfg.pollBackoff(),
+		d.reportState("instance"),
+	)
+}
+
+// waitForVolumeAttachmentState waits for a volume attachment to reach the
+// given terminal state.
+func (d *driverOCI) waitForVolumeAttachmentState(ctx context.Context, id string, waitStates []string, terminalState string) error {
+	return waitForResourceToReachState(
+		ctx,
+		func(string) (string, error) {
+			attachment, err := d.computeClient.GetVolumeAttachment(ctx, core.GetVolumeAttachmentRequest{
+				VolumeAttachmentId: &id,
+				RequestMetadata:    d.requestMetadata,
+			})
+			if err != nil {
+				return "", err
+			}
+			return string(attachment.VolumeAttachment.GetLifecycleState()), nil
+		},
+		id,
+		waitStates,
+		terminalState,
+		d.cfg.StateTimeout,
+		d.cfg.pollBackoff(),
+		d.reportState("volume attachment"),
+	)
+}
+
+// waitForVnicAttachmentState waits for a VNIC attachment to reach the given
+// terminal state.
+func (d *driverOCI) waitForVnicAttachmentState(ctx context.Context, id string, waitStates []string, terminalState string) error {
+	return waitForResourceToReachState(
+		ctx,
+		func(string) (string, error) {
+			attachment, err := d.computeClient.GetVnicAttachment(ctx, core.GetVnicAttachmentRequest{
+				VnicAttachmentId: &id,
+				RequestMetadata:  d.requestMetadata,
+			})
+			if err != nil {
+				return "", err
+			}
+			return string(attachment.LifecycleState), nil
+		},
+		id,
+		waitStates,
 		terminalState,
-		0,             //Unlimited Retries
-		5*time.Second, //5 second wait between retries
+		d.cfg.StateTimeout,
+		d.cfg.pollBackoff(),
+		d.reportState("VNIC attachment"),
 	)
 }
 
+// pollBackoff determines how long to wait between polls of a resource's
+// state. The interval starts at Initial and is multiplied by Multiplier after
+// every poll, up to Max. A Multiplier of 1 or less polls at a constant rate.
+type pollBackoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// next returns the interval to use after the given one.
+func (b pollBackoff) next(interval time.Duration) time.Duration {
+	if b.Multiplier <= 1 {
+		return interval
+	}
+	next := time.Duration(float64(interval) * b.Multiplier)
+	if b.Max > 0 && next > b.Max {
+		next = b.Max
+	}
+	return next
+}
+
+// jitter adds up to 10% of random jitter to the interval when backing off so
+// that parallel builds don't poll in lockstep.
+func (b pollBackoff) jitter(interval time.Duration) time.Duration {
+	if b.Multiplier <= 1 || interval <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Int63n(int64(interval)/10+1))
+}
+
 // WaitForResourceToReachState checks the response of a request through a
-// polled get and waits until the desired state or until the max retried has
-// been reached.
-func waitForResourceToReachState(getResourceState func(string) (string, error), id string, waitStates []string, terminalState string, maxRetries int, waitDuration time.Duration) error {
-	for i := 0; maxRetries == 0 || i < maxRetries; i++ {
+// polled get and waits until the desired state, until the timeout has been
+// reached or until ctx is cancelled. A timeout of zero waits indefinitely.
+func waitForResourceToReachState(ctx context.Context, getResourceState func(string) (string, error), id string, waitStates []string, terminalState string, timeout time.Duration, backoff pollBackoff, onStateChange func(state string, elapsed time.Duration)) error {
+	_, err := waitForResourceToReachStates(ctx, getResourceState, id, waitStates, []string{terminalState}, timeout, backoff, onStateChange)
+	return err
+}
+
+// waitForResourceToReachStates is waitForResourceToReachState for resources
+// that can settle in any one of several terminal states. It returns the
+// terminal state that was reached.
+func waitForResourceToReachStates(ctx context.Context, getResourceState func(string) (string, error), id string, waitStates, terminalStates []string, timeout time.Duration, backoff pollBackoff, onStateChange func(state string, elapsed time.Duration)) (string, error) {
+	start := time.Now()
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = start.Add(timeout)
+	}
+
+	var lastState string
+	interval := backoff.Initial
+	for {
 		state, err := getResourceState(id)
 		if err != nil {
-			return err
+			return "", err
 		}
 
+		if onStateChange != nil && state != lastState {
+			onStateChange(state, time.Since(start))
+		}
+		lastState = state
+
 		if stringSliceContains(waitStates, state) {
-			time.Sleep(waitDuration)
+			wait := backoff.jitter(interval)
+			if !deadline.IsZero() && time.Now().Add(wait).After(deadline) {
+				return "", fmt.Errorf("Timed out after %s waiting for resource to reach state %s, last observed state was %q", timeout, formatStates(terminalStates), state)
+			}
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(wait):
+			}
+			interval = backoff.next(interval)
 			continue
-		} else if state == terminalState {
-			return nil
+		} else if stringSliceContains(terminalStates, state) {
+			return state, nil
 		}
-		return fmt.Errorf("Unexpected resource state %q, expecting a waiting state %s or terminal state  %q ", state, waitStates, terminalState)
+		return "", fmt.Errorf("Unexpected resource state %q, expecting a waiting state %s or terminal state %s", state, waitStates, formatStates(terminalStates))
+	}
+}
+
+// formatStates quotes a single state as before, and lists several as a set.
+func formatStates(states []string) string {
+	if len(states) == 1 {
+		return fmt.Sprintf("%q", states[0])
 	}
-	return fmt.Errorf("Maximum number of retries (%d) exceeded; resource did not reach state %q", maxRetries, terminalState)
+	return fmt.Sprintf("%v", states)
+}
+
+// reportState returns a callback for waitForResourceToReachState that tells
+// the user each new state of the resource, so that long waits are not
+// mistaken for a hung build.
+func (d *driverOCI) reportState(resource string) func(string, time.Duration) {
+	return func(state string, elapsed time.Duration) {
+		message := fmt.Sprintf("%s state: %s (%s elapsed)", resource, state, elapsed.Round(time.Second))
+		if d.ui == nil {
+			log.Printf("[INFO] %s", message)
+			return
+		}
+		d.ui.Message(message)
+	}
+}
+
+// isNotFound reports whether err is the OCI API reporting that a resource
+// doesn't exist, as it does once a deleted resource has been purged.
+func isNotFound(err error) bool {
+	var e common.ServiceError
+	return errors.As(err, &e) && e.GetHTTPStatusCode() == http.StatusNotFound
 }
 
 // stringSliceContains loops through a slice of strings returning a boolean
@@ -0,0 +1,1901 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// statesFunc returns a resource state getter that walks through the given
+// states, repeating the last one once they are exhausted.
+func statesFunc(states ...string) func(string) (string, error) {
+	i := 0
+	return func(string) (string, error) {
+		state := states[i]
+		if i < len(states)-1 {
+			i++
+		}
+		return state, nil
+	}
+}
+
+func TestWaitForResourceToReachState(t *testing.T) {
+	err := waitForResourceToReachState(
+		context.Background(),
+		statesFunc("PROVISIONING", "STARTING", "RUNNING"),
+		"ocid1...",
+		[]string{"PROVISIONING", "STARTING"},
+		"RUNNING",
+		0,
+		pollBackoff{Initial: time.Millisecond},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+func TestWaitForResourceToReachState_ReportsStateChanges(t *testing.T) {
+	var reported []string
+	err := waitForResourceToReachState(
+		context.Background(),
+		statesFunc("PROVISIONING", "PROVISIONING", "STARTING", "STARTING", "RUNNING"),
+		"ocid1...",
+		[]string{"PROVISIONING", "STARTING"},
+		"RUNNING",
+		0,
+		pollBackoff{Initial: time.Millisecond},
+		func(state string, elapsed time.Duration) {
+			reported = append(reported, state)
+		},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if strings.Join(reported, ",") != "PROVISIONING,STARTING,RUNNING" {
+		t.Errorf("Expected each state to be reported once, got %v", reported)
+	}
+}
+
+func TestWaitForResourceToReachState_UnexpectedState(t *testing.T) {
+	err := waitForResourceToReachState(
+		context.Background(),
+		statesFunc("PROVISIONING", "TERMINATED"),
+		"ocid1...",
+		[]string{"PROVISIONING", "STARTING"},
+		"RUNNING",
+		0,
+		pollBackoff{Initial: time.Millisecond},
+		nil,
+	)
+	if err == nil || !strings.Contains(err.Error(), "TERMINATED") {
+		t.Fatalf("Expected unexpected state error, got %v", err)
+	}
+}
+
+func TestWaitForResourceToReachStates(t *testing.T) {
+	state, err := waitForResourceToReachStates(
+		context.Background(),
+		statesFunc("RUNNING", "STOPPING", "STOPPED"),
+		"ocid1...",
+		[]string{"RUNNING", "STOPPING"},
+		[]string{"STOPPED", "TERMINATED"},
+		0,
+		pollBackoff{Initial: time.Millisecond},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if state != "STOPPED" {
+		t.Errorf("Expected to settle in STOPPED, got %s", state)
+	}
+}
+
+func TestWaitForResourceToReachState_Timeout(t *testing.T) {
+	err := waitForResourceToReachState(
+		context.Background(),
+		statesFunc("PROVISIONING"),
+		"ocid1...",
+		[]string{"PROVISIONING"},
+		"RUNNING",
+		10*time.Millisecond,
+		pollBackoff{Initial: time.Millisecond},
+		nil,
+	)
+	if err == nil || !strings.Contains(err.Error(), `last observed state was "PROVISIONING"`) {
+		t.Fatalf("Expected timeout error naming the last state, got %v", err)
+	}
+}
+
+func TestWaitForResourceToReachState_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := waitForResourceToReachState(
+		ctx,
+		statesFunc("PROVISIONING"),
+		"ocid1...",
+		[]string{"PROVISIONING"},
+		"RUNNING",
+		0,
+		pollBackoff{Initial: time.Hour},
+		nil,
+	)
+	if err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestPollBackoff(t *testing.T) {
+	constant := pollBackoff{Initial: time.Second, Multiplier: 1}
+	if next := constant.next(time.Second); next != time.Second {
+		t.Errorf("Expected constant interval, got %s", next)
+	}
+	if wait := constant.jitter(time.Second); wait != time.Second {
+		t.Errorf("Expected no jitter on a constant interval, got %s", wait)
+	}
+
+	exponential := pollBackoff{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2}
+	interval := exponential.Initial
+	expected := []time.Duration{2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for _, e := range expected {
+		interval = exponential.next(interval)
+		if interval != e {
+			t.Errorf("Expected interval %s, got %s", e, interval)
+		}
+	}
+
+	if wait := exponential.jitter(time.Second); wait < time.Second || wait > 1100*time.Millisecond {
+		t.Errorf("Expected jitter of at most 10%%, got %s", wait)
+	}
+}
+
+// serviceErrorMock is a common.ServiceError with the given HTTP status code.
+type serviceErrorMock struct {
+	statusCode int
+}
+
+func (e serviceErrorMock) GetHTTPStatusCode() int  { return e.statusCode }
+func (e serviceErrorMock) GetMessage() string      { return "message" }
+func (e serviceErrorMock) GetCode() string         { return "code" }
+func (e serviceErrorMock) GetOpcRequestID() string { return "opc-request-id" }
+func (e serviceErrorMock) Error() string           { return http.StatusText(e.statusCode) }
+
+func TestShouldRetryOperation(t *testing.T) {
+	tc := []struct {
+		err   error
+		retry bool
+	}{
+		{serviceErrorMock{http.StatusTooManyRequests}, true},
+		{serviceErrorMock{http.StatusInternalServerError}, true},
+		{serviceErrorMock{http.StatusBadGateway}, true},
+		{serviceErrorMock{http.StatusServiceUnavailable}, true},
+		{serviceErrorMock{http.StatusBadRequest}, false},
+		{serviceErrorMock{http.StatusNotFound}, false},
+		{serviceErrorMock{http.StatusConflict}, false},
+		{errors.New("not a service error"), false},
+	}
+
+	for _, c := range tc {
+		if retry := shouldRetryOperation(defaultAPIRetryableStatusCodes)(common.OCIOperationResponse{Error: c.err}); retry != c.retry {
+			t.Errorf("Expected retry of %q to be %t, got %t", c.err, c.retry, retry)
+		}
+	}
+}
+
+func TestNewRequestMetadata(t *testing.T) {
+	metadata := newRequestMetadata(3, []int{http.StatusConflict})
+	if attempts := metadata.RetryPolicy.MaximumNumberAttempts; attempts != 4 {
+		t.Errorf("Expected 4 attempts for 3 retries, got %d", attempts)
+	}
+
+	retry := metadata.RetryPolicy.ShouldRetryOperation
+	if !retry(common.OCIOperationResponse{Error: serviceErrorMock{http.StatusConflict}}) {
+		t.Error("Expected a configured status code to be retried")
+	}
+	if retry(common.OCIOperationResponse{Error: serviceErrorMock{http.StatusTooManyRequests}}) {
+		t.Error("Expected only the configured status codes to be retried")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := uint(1); attempt <= 10; attempt++ {
+		min := time.Duration(1<<attempt) * time.Second
+		if min > maxRetryDelay {
+			min = maxRetryDelay
+		}
+		max := min + 2*time.Second
+		if max > maxRetryDelay {
+			max = maxRetryDelay
+		}
+
+		if delay := retryDelay(attempt); delay < min || delay > max {
+			t.Errorf("Expected the delay after attempt %d to be between %s and %s, got %s", attempt, min, max, delay)
+		}
+	}
+}
+
+func TestDriverOCI_ErrorIncludesOpcRequestID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("opc-request-id", "unique-request-id")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code": "NotAuthorizedOrNotFound", "message": "Authorization failed or requested resource not found."}`))
+	}))
+	defer srv.Close()
+
+	driver, err := NewDriverOCI(baseTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	_, err = d.GetImage(context.Background(), "ocid1.image.oc1..aaaa")
+	if err == nil || !strings.Contains(err.Error(), "unique-request-id") {
+		t.Fatalf("Expected error including the opc-request-id, got %v", err)
+	}
+}
+
+func TestDriverOCI_ListTagNamespaces(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/20160918/tagNamespaces" {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("includeSubcompartments") != "true" {
+			t.Errorf("Expected tag namespaces of subcompartments to be included, got query %s", r.URL.RawQuery)
+		}
+		if r.URL.Query().Get("compartmentId") != "ocid1.tenancy.oc1..aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" {
+			t.Errorf("Expected the tag namespaces of the tenancy, got query %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("opc-next-page", "2")
+			w.Write([]byte(`[{"name": "Operations", "lifecycleState": "ACTIVE"}, {"name": "Retired", "lifecycleState": "INACTIVE"}]`))
+			return
+		}
+		w.Write([]byte(`[{"name": "Build", "lifecycleState": "ACTIVE"}]`))
+	}))
+	defer srv.Close()
+
+	driver, err := NewDriverOCI(baseTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.identityClient.Host = srv.URL
+
+	names, err := d.ListTagNamespaces(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error listing tag namespaces: %s", err)
+	}
+	if strings.Join(names, ",") != "Operations,Build" {
+		t.Errorf("Expected the active tag namespaces of every page, got %v", names)
+	}
+}
+
+func TestDriverOCI_ListTagDefaults(t *testing.T) {
+	namespaceRequests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/20160918/tagDefaults":
+			if id := r.URL.Query().Get("compartmentId"); id != "ocid1.compartment.oc1..aaaa" {
+				t.Errorf("Expected the tag defaults of the compartment, got %s", id)
+			}
+			w.Write([]byte(`[
+				{"tagNamespaceId": "ocid1.tagnamespace.oc1..ops", "tagDefinitionName": "CostCenter", "value": "42", "lifecycleState": "ACTIVE"},
+				{"tagNamespaceId": "ocid1.tagnamespace.oc1..ops", "tagDefinitionName": "Team", "value": "platform", "lifecycleState": "ACTIVE"},
+				{"tagNamespaceId": "ocid1.tagnamespace.oc1..ops", "tagDefinitionName": "Owner", "value": "", "lifecycleState": "ACTIVE"},
+				{"tagNamespaceId": "ocid1.tagnamespace.oc1..ops", "tagDefinitionName": "Retired", "value": "yes", "lifecycleState": "INACTIVE"}
+			]`))
+		case "/20160918/tagNamespaces/ocid1.tagnamespace.oc1..ops":
+			namespaceRequests++
+			w.Write([]byte(`{"id": "ocid1.tagnamespace.oc1..ops", "name": "Operations", "lifecycleState": "ACTIVE"}`))
+		default:
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	driver, err := NewDriverOCI(baseTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.identityClient.Host = srv.URL
+
+	tags, err := d.ListTagDefaults(context.Background(), "ocid1.compartment.oc1..aaaa")
+	if err != nil {
+		t.Fatalf("Unexpected error listing tag defaults: %s", err)
+	}
+	expected := map[string]map[string]interface{}{"Operations": {"CostCenter": "42", "Team": "platform"}}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("Expected tags %v, got %v", expected, tags)
+	}
+	if namespaceRequests != 1 {
+		t.Errorf("Expected the namespace to be looked up once, looked up %d times", namespaceRequests)
+	}
+}
+
+func TestDriverOCI_ListAvailabilityDomains(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/20160918/availabilityDomains" {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("compartmentId") != "ocid1.compartment.oc1..identity" {
+			t.Errorf("Expected the identity compartment, got %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"name": "aaaa:US-ASHBURN-AD-1"}, {"name": "aaaa:US-ASHBURN-AD-2"}]`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.IdentityCompartmentID = "ocid1.compartment.oc1..identity"
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.identityClient.Host = srv.URL
+
+	names, err := d.ListAvailabilityDomains(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error listing availability domains: %s", err)
+	}
+	if strings.Join(names, ",") != "aaaa:US-ASHBURN-AD-1,aaaa:US-ASHBURN-AD-2" {
+		t.Errorf("Expected the availability domain names, got %v", names)
+	}
+}
+
+func TestDriverOCI_ListInstancesByTag(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/20160918/instances" {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("opc-next-page", "2")
+			w.Write([]byte(`[
+				{"id": "ocid1.instance.1", "lifecycleState": "RUNNING", "freeformTags": {"Packer": "true"}},
+				{"id": "ocid1.instance.2", "lifecycleState": "RUNNING", "freeformTags": {"Packer": "false"}},
+				{"id": "ocid1.instance.3", "lifecycleState": "TERMINATED", "freeformTags": {"Packer": "true"}}
+			]`))
+			return
+		}
+		w.Write([]byte(`[{"id": "ocid1.instance.4", "lifecycleState": "STOPPED", "freeformTags": {"Packer": "true"}}]`))
+	}))
+	defer srv.Close()
+
+	driver, err := NewDriverOCI(baseTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	ids, err := d.ListInstancesByTag(context.Background(), instancePackerTagKey, "true")
+	if err != nil {
+		t.Fatalf("Unexpected error listing instances: %s", err)
+	}
+	if strings.Join(ids, ",") != "ocid1.instance.1,ocid1.instance.4" {
+		t.Errorf("Expected the tagged instances of every page, got %v", ids)
+	}
+}
+
+func TestDriverOCI_HTTPTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.image.oc1..aaaa"}`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.HTTPTimeout = 10 * time.Millisecond
+	apiMaxRetries := 0
+	config.APIMaxRetries = &apiMaxRetries
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	if _, err := d.GetImage(context.Background(), "ocid1.image.oc1..aaaa"); err == nil || !strings.Contains(err.Error(), "Timeout") {
+		t.Fatalf("Expected the request to time out, got %v", err)
+	}
+}
+
+func TestDriverOCI_HTTPTimeoutUnset(t *testing.T) {
+	config := baseTestConfig()
+	config.HTTPTimeout = 0
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+
+	httpClient, ok := d.computeClient.HTTPClient.(*http.Client)
+	if !ok {
+		t.Fatalf("Expected the SDK's HTTP client, got %T", d.computeClient.HTTPClient)
+	}
+	if httpClient.Timeout != defaultHTTPTimeout {
+		t.Errorf("Expected the SDK's timeout of %s to be kept, got %s", defaultHTTPTimeout, httpClient.Timeout)
+	}
+}
+
+func TestDriverOCI_ServiceEndpointOverride(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/20160918/images/ocid1.image.oc1..aaaa" {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.image.oc1..aaaa"}`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.ServiceEndpointOverride = srv.URL
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	if d.vcnClient.Host != srv.URL || d.blockstorageClient.Host != srv.URL {
+		t.Errorf("Expected the virtual network and block storage clients to use %s", srv.URL)
+	}
+	if d.identityClient.Host == srv.URL {
+		t.Errorf("Expected the identity client not to use %s", srv.URL)
+	}
+
+	if _, err := d.GetImage(context.Background(), "ocid1.image.oc1..aaaa"); err != nil {
+		t.Fatalf("Expected the request to go to the override endpoint, got %s", err)
+	}
+}
+
+func TestDriverOCI_IdentityEndpointOverride(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/20160918/availabilityDomains" {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"name": "aaaa:US-ASHBURN-AD-1"}]`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.IdentityEndpointOverride = srv.URL
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+
+	ads, err := driver.ListAvailabilityDomains(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the request to go to the override endpoint, got %s", err)
+	}
+	if len(ads) != 1 || ads[0] != "aaaa:US-ASHBURN-AD-1" {
+		t.Errorf("Unexpected availability domains %v", ads)
+	}
+}
+
+func TestDriverOCI_ListShapes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/20160918/shapes" {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("availabilityDomain") != "aaaa:US-ASHBURN-AD-1" || query.Get("imageId") != "ocid1.image.oc1.iad.aaaa" {
+			t.Errorf("Expected shapes to be filtered by availability domain and image, got %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if query.Get("page") == "" {
+			w.Header().Set("opc-next-page", "2")
+			w.Write([]byte(`[{"shape": "VM.Standard2.1"}]`))
+			return
+		}
+		w.Write([]byte(`[{"shape": "VM.Standard.E3.Flex"}]`))
+	}))
+	defer srv.Close()
+
+	driver, err := NewDriverOCI(baseTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	shapes, err := d.ListShapes(context.Background(), "ocid1.image.oc1.iad.aaaa")
+	if err != nil {
+		t.Fatalf("Unexpected error listing shapes: %s", err)
+	}
+	if strings.Join(shapes, ",") != "VM.Standard2.1,VM.Standard.E3.Flex" {
+		t.Errorf("Expected the shapes of every page, got %v", shapes)
+	}
+}
+
+func TestDriverOCI_ListSubnets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/20160918/subnets" {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("vcnId") != "ocid1.vcn.oc1.iad.aaaa" || query.Get("displayName") != "private" {
+			t.Errorf("Expected subnets to be filtered by VCN and name, got %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"id": "ocid1.subnet.regional", "lifecycleState": "AVAILABLE"},
+			{"id": "ocid1.subnet.ad1", "lifecycleState": "AVAILABLE", "availabilityDomain": "aaaa:US-ASHBURN-AD-1"},
+			{"id": "ocid1.subnet.ad2", "lifecycleState": "AVAILABLE", "availabilityDomain": "aaaa:US-ASHBURN-AD-2"}
+		]`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.AvailabilityDomain = "aaaa:US-ASHBURN-AD-1"
+	config.VcnID = "ocid1.vcn.oc1.iad.aaaa"
+	config.SubnetName = "private"
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.vcnClient.Host = srv.URL
+
+	subnets, err := d.ListSubnets(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error listing subnets: %s", err)
+	}
+	var ids []string
+	for _, subnet := range subnets {
+		ids = append(ids, *subnet.Id)
+	}
+	if strings.Join(ids, ",") != "ocid1.subnet.regional,ocid1.subnet.ad1" {
+		t.Errorf("Expected the subnets usable in the availability domain, got %v", ids)
+	}
+}
+
+func TestDriverOCI_GetInstanceIP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/20160918/vnicAttachments" && r.URL.Query().Get("page") == "":
+			w.Header().Set("opc-next-page", "2")
+			w.Write([]byte(`[
+				{"vnicId": "ocid1.vnic.detached", "lifecycleState": "DETACHED"},
+				{"vnicId": "ocid1.vnic.secondary", "lifecycleState": "ATTACHED"}
+			]`))
+		case r.URL.Path == "/20160918/vnicAttachments":
+			w.Write([]byte(`[{"vnicId": "ocid1.vnic.primary", "lifecycleState": "ATTACHED"}]`))
+		case r.URL.Path == "/20160918/vnics/ocid1.vnic.secondary":
+			w.Write([]byte(`{"isPrimary": false, "publicIp": "192.0.2.2"}`))
+		case r.URL.Path == "/20160918/vnics/ocid1.vnic.primary":
+			w.Write([]byte(`{"isPrimary": true, "publicIp": "192.0.2.1"}`))
+		default:
+			t.Errorf("Unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	driver, err := NewDriverOCI(baseTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+	d.vcnClient.Host = srv.URL
+
+	ip, err := d.GetInstanceIP(context.Background(), "ocid1.instance.oc1..aaaa")
+	if err != nil {
+		t.Fatalf("Unexpected error getting instance IP: %s", err)
+	}
+	if ip != "192.0.2.1" {
+		t.Errorf("Expected the primary VNIC's IP, got %s", ip)
+	}
+}
+
+func TestDriverOCI_GetInstanceIPNoPrimaryVnic(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/20160918/vnicAttachments":
+			w.Write([]byte(`[{"vnicId": "ocid1.vnic.secondary", "lifecycleState": "ATTACHED"}]`))
+		case "/20160918/vnics/ocid1.vnic.secondary":
+			w.Write([]byte(`{"isPrimary": false, "publicIp": "192.0.2.2"}`))
+		default:
+			t.Errorf("Unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	driver, err := NewDriverOCI(baseTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+	d.vcnClient.Host = srv.URL
+
+	_, err = d.GetInstanceIP(context.Background(), "ocid1.instance.oc1..aaaa")
+	if err == nil || !strings.Contains(err.Error(), "no attached primary VNIC") {
+		t.Fatalf("Expected no primary VNIC error, got %v", err)
+	}
+}
+
+func TestDriverOCI_GetInstanceIPv6(t *testing.T) {
+	for name, tc := range map[string]struct {
+		lists  int
+		usable int
+		ipv6s  string
+		subnet string
+		ip     string
+		err    string
+	}{
+		"Assigned":    {0, 0, `[{"ipAddress": "2001:db8::1", "lifecycleState": "AVAILABLE"}]`, "", "2001:db8::1", ""},
+		"Late":        {0, 3, `[{"ipAddress": "2001:db8::1", "lifecycleState": "AVAILABLE"}]`, `{"ipv6CidrBlock": "2001:db8::/64"}`, "2001:db8::1", ""},
+		"Unassigned":  {0, 0, `[]`, `{"ipv6CidrBlock": "2001:db8::/64"}`, "", "has no IPv6 address assigned to VNIC"},
+		"Unsupported": {0, 0, `[]`, `{}`, "", "does not support IPv6"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/20160918/vnicAttachments":
+					w.Write([]byte(`[{"vnicId": "ocid1.vnic.primary", "lifecycleState": "ATTACHED"}]`))
+				case "/20160918/vnics/ocid1.vnic.primary":
+					w.Write([]byte(`{"id": "ocid1.vnic.primary", "isPrimary": true, "publicIp": "192.0.2.1", "subnetId": "ocid1.subnet.oc1..aaaa"}`))
+				case "/20160918/ipv6":
+					if vnicID := r.URL.Query().Get("vnicId"); vnicID != "ocid1.vnic.primary" {
+						t.Errorf("Expected IPv6 addresses of the primary VNIC, got %s", vnicID)
+					}
+					tc.lists++
+					if tc.lists < tc.usable {
+						w.Write([]byte(`[]`))
+						return
+					}
+					w.Write([]byte(tc.ipv6s))
+				case "/20160918/subnets/ocid1.subnet.oc1..aaaa":
+					w.Write([]byte(tc.subnet))
+				default:
+					t.Errorf("Unexpected request %s", r.URL)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer srv.Close()
+
+			config := baseTestConfig()
+			config.IPVersion = ipVersionIPv6
+			config.StatePollInterval = time.Millisecond
+			config.StateTimeout = 50 * time.Millisecond
+
+			driver, err := NewDriverOCI(config, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error creating driver: %s", err)
+			}
+			d := driver.(*driverOCI)
+			d.computeClient.Host = srv.URL
+			d.vcnClient.Host = srv.URL
+
+			ip, err := d.GetInstanceIP(context.Background(), "ocid1.instance.oc1..aaaa")
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error getting instance IP: %s", err)
+				}
+				if ip != tc.ip {
+					t.Errorf("Expected the VNIC's IPv6 address %s, got %s", tc.ip, ip)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("Expected error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestDriverOCI_RemovePublicIP(t *testing.T) {
+	for name, tc := range map[string]struct {
+		lifetime string
+		deleted  bool
+		err      string
+	}{
+		"Ephemeral": {"EPHEMERAL", true, ""},
+		"Reserved":  {"RESERVED", false, "is RESERVED rather than EPHEMERAL"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			deleted := false
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.URL.Path == "/20160918/vnicAttachments":
+					w.Write([]byte(`[
+						{"vnicId": "ocid1.vnic.secondary", "lifecycleState": "ATTACHED"},
+						{"vnicId": "ocid1.vnic.primary", "lifecycleState": "ATTACHED"}
+					]`))
+				case r.URL.Path == "/20160918/vnics/ocid1.vnic.secondary":
+					w.Write([]byte(`{"isPrimary": false, "publicIp": "192.0.2.2"}`))
+				case r.URL.Path == "/20160918/vnics/ocid1.vnic.primary":
+					w.Write([]byte(`{"isPrimary": true, "publicIp": "192.0.2.1"}`))
+				case r.URL.Path == "/20160918/publicIps/actions/getByIpAddress":
+					var details core.GetPublicIpByIpAddressDetails
+					json.NewDecoder(r.Body).Decode(&details)
+					if details.IpAddress == nil || *details.IpAddress != "192.0.2.1" {
+						t.Errorf("Expected the primary VNIC's public IP to be looked up, got %v", details.IpAddress)
+					}
+					fmt.Fprintf(w, `{"id": "ocid1.publicip.oc1..aaaa", "lifetime": %q}`, tc.lifetime)
+				case r.URL.Path == "/20160918/publicIps/ocid1.publicip.oc1..aaaa" && r.Method == http.MethodDelete:
+					deleted = true
+					w.WriteHeader(http.StatusNoContent)
+				case r.URL.Path == "/20160918/publicIps/ocid1.publicip.oc1..aaaa":
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(`{"code": "NotAuthorizedOrNotFound"}`))
+				default:
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer srv.Close()
+
+			driver, err := NewDriverOCI(baseTestConfig(), nil)
+			if err != nil {
+				t.Fatalf("Unexpected error creating driver: %s", err)
+			}
+			d := driver.(*driverOCI)
+			d.computeClient.Host = srv.URL
+			d.vcnClient.Host = srv.URL
+
+			err = d.RemovePublicIP(context.Background(), "ocid1.instance.oc1..aaaa")
+			if tc.err == "" && err != nil {
+				t.Fatalf("Unexpected error removing public IP: %s", err)
+			}
+			if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+				t.Fatalf("Expected error containing %q, got %v", tc.err, err)
+			}
+			if deleted != tc.deleted {
+				t.Errorf("Expected public IP deleted to be %t, got %t", tc.deleted, deleted)
+			}
+		})
+	}
+}
+
+func TestDriverOCI_GetInstanceIPWaitsForVnics(t *testing.T) {
+	lists := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/20160918/vnicAttachments":
+			lists++
+			if lists < 3 {
+				w.Write([]byte(`[]`))
+				return
+			}
+			w.Write([]byte(`[{"vnicId": "ocid1.vnic.primary", "lifecycleState": "ATTACHED"}]`))
+		case "/20160918/vnics/ocid1.vnic.primary":
+			w.Write([]byte(`{"isPrimary": true, "publicIp": "192.0.2.1"}`))
+		default:
+			t.Errorf("Unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.StatePollInterval = time.Millisecond
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+	d.vcnClient.Host = srv.URL
+
+	ip, err := d.GetInstanceIP(context.Background(), "ocid1.instance.oc1..aaaa")
+	if err != nil {
+		t.Fatalf("Unexpected error getting instance IP: %s", err)
+	}
+	if ip != "192.0.2.1" || lists != 3 {
+		t.Errorf("Expected to wait for the primary VNIC's IP, got %s after %d lists", ip, lists)
+	}
+}
+
+func TestDriverOCI_GetInstanceIPWaitsForIP(t *testing.T) {
+	for name, tc := range map[string]struct {
+		vnics  int
+		vnic   string
+		usable int
+		err    string
+	}{
+		"Public":  {0, `{"id": "ocid1.vnic.primary", "isPrimary": true, "publicIp": "192.0.2.1"}`, 3, ""},
+		"Private": {0, `{"id": "ocid1.vnic.primary", "isPrimary": true, "privateIp": "192.0.2.1"}`, 3, ""},
+		"Never":   {0, `{"id": "ocid1.vnic.primary", "isPrimary": true, "publicIp": "192.0.2.1"}`, 1000, "has no public IP on VNIC ocid1.vnic.primary"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/20160918/vnicAttachments":
+					w.Write([]byte(`[{"vnicId": "ocid1.vnic.primary", "lifecycleState": "ATTACHED"}]`))
+				case "/20160918/vnics/ocid1.vnic.primary":
+					tc.vnics++
+					if tc.vnics < tc.usable {
+						w.Write([]byte(`{"id": "ocid1.vnic.primary", "isPrimary": true}`))
+						return
+					}
+					w.Write([]byte(tc.vnic))
+				default:
+					t.Errorf("Unexpected request %s", r.URL)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer srv.Close()
+
+			config := baseTestConfig()
+			config.StatePollInterval = time.Millisecond
+			config.StateTimeout = 50 * time.Millisecond
+			config.UsePrivateIP = name == "Private"
+
+			driver, err := NewDriverOCI(config, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error creating driver: %s", err)
+			}
+			d := driver.(*driverOCI)
+			d.computeClient.Host = srv.URL
+			d.vcnClient.Host = srv.URL
+
+			ip, err := d.GetInstanceIP(context.Background(), "ocid1.instance.oc1..aaaa")
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("Expected error containing %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error getting instance IP: %s", err)
+			}
+			if ip != "192.0.2.1" || tc.vnics != tc.usable {
+				t.Errorf("Expected to wait for the VNIC's IP, got %q after %d gets", ip, tc.vnics)
+			}
+		})
+	}
+}
+
+func TestDriverOCI_GetInstanceIPNetworkCompartment(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/20160918/vnicAttachments":
+			if got := r.URL.Query().Get("compartmentId"); got != "ocid1.compartment.oc1..network" {
+				t.Errorf("Expected VNIC attachments to be listed in the network compartment, got %q", got)
+			}
+			w.Write([]byte(`[{"vnicId": "ocid1.vnic.primary", "lifecycleState": "ATTACHED"}]`))
+		case "/20160918/vnics/ocid1.vnic.primary":
+			w.Write([]byte(`{"isPrimary": true, "publicIp": "192.0.2.1"}`))
+		default:
+			t.Errorf("Unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.NetworkCompartmentID = "ocid1.compartment.oc1..network"
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+	d.vcnClient.Host = srv.URL
+
+	if _, err := d.GetInstanceIP(context.Background(), "ocid1.instance.oc1..aaaa"); err != nil {
+		t.Fatalf("Unexpected error getting instance IP: %s", err)
+	}
+}
+
+func TestDriverOCI_GetInstanceIPNoVnics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.StatePollInterval = time.Millisecond
+	config.StateTimeout = 10 * time.Millisecond
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	_, err = d.GetInstanceIP(context.Background(), "ocid1.instance.oc1..aaaa")
+	if err == nil || !strings.Contains(err.Error(), "has no attached VNICs") {
+		t.Fatalf("Expected no VNICs error, got %v", err)
+	}
+}
+
+// vnicServer serves the VNIC attachments and VNICs of an instance with a
+// primary VNIC attached first and a secondary "management" VNIC attached
+// second, listed in reverse order.
+func vnicServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/20160918/vnicAttachments":
+			w.Write([]byte(`[
+				{"vnicId": "ocid1.vnic.secondary", "lifecycleState": "ATTACHED", "timeCreated": "2020-01-01T00:01:00Z"},
+				{"vnicId": "ocid1.vnic.primary", "lifecycleState": "ATTACHED", "timeCreated": "2020-01-01T00:00:00Z"}
+			]`))
+		case "/20160918/vnics/ocid1.vnic.secondary":
+			w.Write([]byte(`{"isPrimary": false, "displayName": "management", "publicIp": "192.0.2.2"}`))
+		case "/20160918/vnics/ocid1.vnic.primary":
+			w.Write([]byte(`{"isPrimary": true, "displayName": "primary", "publicIp": "192.0.2.1"}`))
+		default:
+			t.Errorf("Unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestDriverOCI_GetInstanceIPCommVnic(t *testing.T) {
+	srv := vnicServer(t)
+	defer srv.Close()
+
+	index := 1
+	tc := []struct {
+		name     string
+		index    *int
+		vnicName string
+		ip       string
+	}{
+		{name: "Primary", ip: "192.0.2.1"},
+		{name: "Index", index: &index, ip: "192.0.2.2"},
+		{name: "Name", vnicName: "management", ip: "192.0.2.2"},
+	}
+
+	for _, c := range tc {
+		t.Run(c.name, func(t *testing.T) {
+			config := baseTestConfig()
+			config.CommVnicIndex = c.index
+			config.CommVnicName = c.vnicName
+
+			driver, err := NewDriverOCI(config, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error creating driver: %s", err)
+			}
+			d := driver.(*driverOCI)
+			d.computeClient.Host = srv.URL
+			d.vcnClient.Host = srv.URL
+
+			ip, err := d.GetInstanceIP(context.Background(), "ocid1.instance.oc1..aaaa")
+			if err != nil {
+				t.Fatalf("Unexpected error getting instance IP: %s", err)
+			}
+			if ip != c.ip {
+				t.Errorf("Expected IP %s, got %s", c.ip, ip)
+			}
+		})
+	}
+}
+
+func TestDriverOCI_GetInstanceIPCommVnicMissing(t *testing.T) {
+	srv := vnicServer(t)
+	defer srv.Close()
+
+	index := 2
+	indexConfig := baseTestConfig()
+	indexConfig.CommVnicIndex = &index
+	nameConfig := baseTestConfig()
+	nameConfig.CommVnicName = "backup"
+
+	for _, config := range []*Config{indexConfig, nameConfig} {
+		driver, err := NewDriverOCI(config, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error creating driver: %s", err)
+		}
+		d := driver.(*driverOCI)
+		d.computeClient.Host = srv.URL
+		d.vcnClient.Host = srv.URL
+
+		if _, err := d.GetInstanceIP(context.Background(), "ocid1.instance.oc1..aaaa"); err == nil {
+			t.Errorf("Expected error for a missing VNIC")
+		}
+	}
+}
+
+func TestDriverOCI_CreateInstanceCapacityReservation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/20160918/instances" {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+
+		var details map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+			t.Errorf("Unexpected error decoding launch details: %s", err)
+		}
+		if id := details["capacityReservationId"]; id != "ocid1.capacityreservation.oc1.iad.aaaa" {
+			t.Errorf("Expected capacity reservation in launch details, got %v", id)
+		}
+		if shape := details["shape"]; shape != "VM.Standard1.1" {
+			t.Errorf("Expected SDK launch details to be preserved, got shape %v", shape)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.instance.oc1..aaaa"}`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.CapacityReservationID = "ocid1.capacityreservation.oc1.iad.aaaa"
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	id, err := d.CreateInstance(context.Background(), "ssh-rsa AAAA...")
+	if err != nil {
+		t.Fatalf("Unexpected error creating instance: %s", err)
+	}
+	if id != "ocid1.instance.oc1..aaaa" {
+		t.Errorf("Expected instance id ocid1.instance.oc1..aaaa, got %s", id)
+	}
+}
+
+func TestDriverOCI_MarketplaceListing(t *testing.T) {
+	subscribed := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/20160918/appCatalogListings/ocid1.appcataloglisting.oc1..aaaa/resourceVersions":
+			if order := r.URL.Query().Get("sortOrder"); order != "DESC" {
+				t.Errorf("Expected the most recent version first, got sort order %q", order)
+			}
+			w.Write([]byte(`[{"listingResourceVersion": "2.0"}, {"listingResourceVersion": "1.0"}]`))
+		case "/20160918/appCatalogListings/ocid1.appcataloglisting.oc1..aaaa/resourceVersions/2.0":
+			w.Write([]byte(`{"listingResourceId": "ocid1.image.oc1..marketplace", "listingResourceVersion": "2.0"}`))
+		case "/20160918/appCatalogListings/ocid1.appcataloglisting.oc1..aaaa/resourceVersions/2.0/agreements":
+			w.Write([]byte(`{"oracleTermsOfUseLink": "https://example.com/terms", "timeRetrieved": "2020-01-01T00:00:00Z", "signature": "signed"}`))
+		case "/20160918/appCatalogSubscriptions":
+			var details map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+				t.Errorf("Unexpected error decoding subscription details: %s", err)
+			}
+			if details["signature"] != "signed" || details["listingResourceVersion"] != "2.0" || details["compartmentId"] == nil {
+				t.Errorf("Expected the agreement to be signed for the compartment, got %v", details)
+			}
+			subscribed = true
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("Unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.MarketplaceListingID = "ocid1.appcataloglisting.oc1..aaaa"
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	version, err := d.GetMarketplaceListingVersion(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error getting listing version: %s", err)
+	}
+	if *version.ListingResourceId != "ocid1.image.oc1..marketplace" {
+		t.Errorf("Expected the listing's image, got %s", *version.ListingResourceId)
+	}
+
+	if err := d.SubscribeMarketplaceListing(context.Background(), *version.ListingResourceVersion); err != nil {
+		t.Fatalf("Unexpected error subscribing to listing: %s", err)
+	}
+	if !subscribed {
+		t.Error("Expected a subscription to be created")
+	}
+}
+
+func TestDriverOCI_CreateInstanceShapeConfig(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var details struct {
+			ShapeConfig map[string]interface{} `json:"shapeConfig"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+			t.Errorf("Unexpected error decoding launch details: %s", err)
+		}
+		expected := map[string]interface{}{"ocpus": float64(2), "memoryInGBs": float64(32)}
+		if !reflect.DeepEqual(details.ShapeConfig, expected) {
+			t.Errorf("Expected shape config %v in launch details, got %v", expected, details.ShapeConfig)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.instance.oc1..aaaa"}`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.Shape = "VM.Standard.E3.Flex"
+	config.ShapeOCPUs = 2
+	config.ShapeMemoryInGBs = 32
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	if _, err := d.CreateInstance(context.Background(), "ssh-rsa AAAA..."); err != nil {
+		t.Fatalf("Unexpected error creating instance: %s", err)
+	}
+}
+
+func TestDriverOCI_CreateInstanceKmsKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var details struct {
+			SourceDetails map[string]interface{} `json:"sourceDetails"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+			t.Errorf("Unexpected error decoding launch details: %s", err)
+		}
+		if id := details.SourceDetails["kmsKeyId"]; id != "ocid1.key.oc1.iad.aaaa" {
+			t.Errorf("Expected KMS key in the launch source details, got %v", id)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.instance.oc1..aaaa"}`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.KmsKeyID = "ocid1.key.oc1.iad.aaaa"
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	if _, err := d.CreateInstance(context.Background(), "ssh-rsa AAAA..."); err != nil {
+		t.Fatalf("Unexpected error creating instance: %s", err)
+	}
+}
+
+func TestDriverOCI_CreateInstanceBootVolumeVpus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var details struct {
+			SourceDetails map[string]interface{} `json:"sourceDetails"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+			t.Errorf("Unexpected error decoding launch details: %s", err)
+		}
+		if vpus := details.SourceDetails["bootVolumeVpusPerGB"]; vpus != float64(30) {
+			t.Errorf("Expected 30 VPUs per GB in the launch source details, got %v", vpus)
+		}
+		if details.SourceDetails["sourceType"] != "image" || details.SourceDetails["bootVolumeSizeInGBs"] != float64(100) {
+			t.Errorf("Expected the image source details to be kept, got %v", details.SourceDetails)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.instance.oc1..aaaa"}`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.BootVolumeSizeInGBs = 100
+	config.BootVolumeVpusPerGB = 30
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	if _, err := d.CreateInstance(context.Background(), "ssh-rsa AAAA..."); err != nil {
+		t.Fatalf("Unexpected error creating instance: %s", err)
+	}
+}
+
+func TestDriverOCI_CreateInstanceTagged(t *testing.T) {
+	for name, tc := range map[string]struct {
+		instanceTags map[string]string
+		expected     map[string]interface{}
+	}{
+		"Unset":      {nil, map[string]interface{}{"Packer": "true"}},
+		"Merged":     {map[string]string{"key": "value"}, map[string]interface{}{"Packer": "true", "key": "value"}},
+		"Overridden": {map[string]string{"Packer": "override"}, map[string]interface{}{"Packer": "override"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var details struct {
+					FreeformTags map[string]interface{} `json:"freeformTags"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+					t.Errorf("Unexpected error decoding launch details: %s", err)
+				}
+				if !reflect.DeepEqual(details.FreeformTags, tc.expected) {
+					t.Errorf("Expected freeform tags %v in launch details, got %v", tc.expected, details.FreeformTags)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id": "ocid1.instance.oc1..aaaa"}`))
+			}))
+			defer srv.Close()
+
+			config := baseTestConfig()
+			config.InstanceTags = tc.instanceTags
+
+			driver, err := NewDriverOCI(config, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error creating driver: %s", err)
+			}
+			d := driver.(*driverOCI)
+			d.computeClient.Host = srv.URL
+
+			if _, err := d.CreateInstance(context.Background(), "ssh-rsa AAAA..."); err != nil {
+				t.Fatalf("Unexpected error creating instance: %s", err)
+			}
+		})
+	}
+}
+
+func TestDriverOCI_CreateInstanceAgentConfig(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var details struct {
+			AgentConfig *launchInstanceAgentConfig `json:"agentConfig"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+			t.Errorf("Unexpected error decoding launch details: %s", err)
+		}
+		expected := &launchInstanceAgentConfig{
+			PluginsConfig: []instanceAgentPluginConfig{
+				{Name: "Vulnerability Scanning", DesiredState: "DISABLED"},
+				{Name: "OS Management Service Agent", DesiredState: "DISABLED"},
+			},
+		}
+		if !reflect.DeepEqual(details.AgentConfig, expected) {
+			t.Errorf("Expected agent config %+v, got %+v", expected, details.AgentConfig)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.instance.oc1..aaaa"}`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.AgentDisabledPlugins = []string{"Vulnerability Scanning", "OS Management Service Agent"}
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	if _, err := d.CreateInstance(context.Background(), "ssh-rsa AAAA..."); err != nil {
+		t.Fatalf("Unexpected error creating instance: %s", err)
+	}
+}
+
+func TestDriverOCI_CreateImageCompartment(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var details struct {
+			CompartmentID string `json:"compartmentId"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+			t.Errorf("Unexpected error decoding image details: %s", err)
+		}
+		if details.CompartmentID != "ocid1.compartment.oc1..images" {
+			t.Errorf("Expected the image to be created in image_compartment_ocid, got %q", details.CompartmentID)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.image.oc1..aaaa"}`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.ImageCompartmentID = "ocid1.compartment.oc1..images"
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	if _, err := d.CreateImage(context.Background(), "ocid1.instance.oc1..aaaa"); err != nil {
+		t.Fatalf("Unexpected error creating image: %s", err)
+	}
+}
+
+func TestDriverOCI_CreateImageTagBaseImage(t *testing.T) {
+	baseImageID := "ocid1.image.oc1.iad.resolved"
+	for name, tc := range map[string]struct {
+		tagBaseImage bool
+		baseImageID  string
+		imageTags    map[string]string
+		expected     map[string]interface{}
+	}{
+		"Disabled":    {false, baseImageID, map[string]string{"key": "value"}, map[string]interface{}{"key": "value"}},
+		"Merged":      {true, baseImageID, map[string]string{"key": "value"}, map[string]interface{}{"base_image": baseImageID, "key": "value"}},
+		"Overridden":  {true, baseImageID, map[string]string{"base_image": "override"}, map[string]interface{}{"base_image": "override"}},
+		"NoBaseImage": {true, "", nil, nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var details struct {
+					FreeformTags map[string]interface{} `json:"freeformTags"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+					t.Errorf("Unexpected error decoding image details: %s", err)
+				}
+				if !reflect.DeepEqual(details.FreeformTags, tc.expected) {
+					t.Errorf("Expected freeform tags %v in image details, got %v", tc.expected, details.FreeformTags)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id": "ocid1.image.oc1..aaaa"}`))
+			}))
+			defer srv.Close()
+
+			config := baseTestConfig()
+			config.TagBaseImage = tc.tagBaseImage
+			config.BaseImageID = tc.baseImageID
+			config.ImageTags = tc.imageTags
+
+			driver, err := NewDriverOCI(config, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error creating driver: %s", err)
+			}
+			d := driver.(*driverOCI)
+			d.computeClient.Host = srv.URL
+
+			if _, err := d.CreateImage(context.Background(), "ocid1.instance.oc1..aaaa"); err != nil {
+				t.Fatalf("Unexpected error creating image: %s", err)
+			}
+		})
+	}
+}
+
+func TestDriverOCI_CreateInstanceLegacyImdsDisabled(t *testing.T) {
+	for name, disabled := range map[string]bool{"Disabled": true, "Default": false} {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var details struct {
+					InstanceOptions *instanceOptions `json:"instanceOptions"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+					t.Errorf("Unexpected error decoding launch details: %s", err)
+				}
+				if o := details.InstanceOptions; disabled && (o == nil || o.AreLegacyImdsEndpointsDisabled == nil || !*o.AreLegacyImdsEndpointsDisabled) {
+					t.Errorf("Expected the legacy IMDS endpoints to be disabled, got %+v", o)
+				} else if !disabled && o != nil {
+					t.Errorf("Expected no instance options by default, got %+v", o)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id": "ocid1.instance.oc1..aaaa"}`))
+			}))
+			defer srv.Close()
+
+			config := baseTestConfig()
+			config.DisableLegacyImdsEndpoints = disabled
+
+			driver, err := NewDriverOCI(config, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error creating driver: %s", err)
+			}
+			d := driver.(*driverOCI)
+			d.computeClient.Host = srv.URL
+
+			if _, err := d.CreateInstance(context.Background(), "ssh-rsa AAAA..."); err != nil {
+				t.Fatalf("Unexpected error creating instance: %s", err)
+			}
+		})
+	}
+}
+
+func TestDriverOCI_CreateInstanceShielded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var details struct {
+			PlatformConfig *platformConfig `json:"platformConfig"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+			t.Errorf("Unexpected error decoding launch details: %s", err)
+		}
+		if c := details.PlatformConfig; c == nil || c.Type != "AMD_MILAN_BM" || !*c.IsSecureBootEnabled ||
+			*c.IsMeasuredBootEnabled || !*c.IsTrustedPlatformModuleEnabled {
+			t.Errorf("Expected an AMD Milan platform config with secure boot and a TPM, got %+v", c)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.instance.oc1..aaaa"}`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.Shape = "BM.Standard.E4.128"
+	config.SecureBoot = true
+	config.TrustedPlatformModule = true
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	if _, err := d.CreateInstance(context.Background(), "ssh-rsa AAAA..."); err != nil {
+		t.Fatalf("Unexpected error creating instance: %s", err)
+	}
+}
+
+func TestDriverOCI_CreateInstancePreemptible(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var details struct {
+			PreemptibleInstanceConfig *preemptibleInstanceConfig `json:"preemptibleInstanceConfig"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+			t.Errorf("Unexpected error decoding launch details: %s", err)
+		}
+		if c := details.PreemptibleInstanceConfig; c == nil || c.PreemptionAction.Type != "TERMINATE" ||
+			c.PreemptionAction.PreserveBootVolume == nil || *c.PreemptionAction.PreserveBootVolume {
+			t.Errorf("Expected a preemptible config terminating the boot volume, got %+v", c)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.instance.oc1..aaaa"}`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.Preemptible = true
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	if _, err := d.CreateInstance(context.Background(), "ssh-rsa AAAA..."); err != nil {
+		t.Fatalf("Unexpected error creating instance: %s", err)
+	}
+}
+
+func TestDriverOCI_TerminateInstanceAlreadyTerminated(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"code": "Conflict", "message": "Instance is terminated"}`))
+		case http.MethodGet:
+			w.Write([]byte(`{"id": "ocid1.instance.oc1..aaaa", "lifecycleState": "TERMINATED"}`))
+		}
+	}))
+	defer srv.Close()
+
+	driver, err := NewDriverOCI(baseTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	if err := d.TerminateInstance(context.Background(), "ocid1.instance.oc1..aaaa"); err != nil {
+		t.Fatalf("Expected terminating a terminated instance to succeed, got %s", err)
+	}
+}
+
+func TestDriverOCI_DeleteNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code": "NotAuthorizedOrNotFound", "message": "Authorization failed or requested resource not found."}`))
+	}))
+	defer srv.Close()
+
+	driver, err := NewDriverOCI(baseTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+	d.blockstorageClient.Host = srv.URL
+
+	if err := d.TerminateInstance(context.Background(), "ocid1.instance.oc1..aaaa"); err != nil {
+		t.Errorf("Expected terminating a missing instance to succeed, got %s", err)
+	}
+	if err := d.DeleteImage(context.Background(), "ocid1.image.oc1..aaaa"); err != nil {
+		t.Errorf("Expected deleting a missing image to succeed, got %s", err)
+	}
+	if err := d.DeleteVolume(context.Background(), "ocid1.volume.oc1..aaaa"); err != nil {
+		t.Errorf("Expected deleting a missing volume to succeed, got %s", err)
+	}
+}
+
+func TestDriverOCI_ImportImage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/20160918/images" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+
+		var details struct {
+			ImageSourceDetails map[string]string `json:"imageSourceDetails"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+			t.Errorf("Unexpected error decoding image details: %s", err)
+		}
+		expected := map[string]string{
+			"sourceType":      "objectStorageTuple",
+			"bucketName":      "images",
+			"namespaceName":   "namespace",
+			"objectName":      "base.qcow2",
+			"sourceImageType": "QCOW2",
+		}
+		if !reflect.DeepEqual(details.ImageSourceDetails, expected) {
+			t.Errorf("Expected image source %v, got %v", expected, details.ImageSourceDetails)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.image.oc1..aaaa", "lifecycleState": "IMPORTING"}`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.BaseImageImportBucket = "images"
+	config.BaseImageImportNamespace = "namespace"
+	config.BaseImageImportName = "base.qcow2"
+	config.BaseImageImportFormat = "QCOW2"
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	image, err := d.ImportImage(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error importing image: %s", err)
+	}
+	if *image.Id != "ocid1.image.oc1..aaaa" {
+		t.Errorf("Expected image ocid1.image.oc1..aaaa, got %s", *image.Id)
+	}
+}
+
+func TestDriverOCI_ExportImageFormat(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"id": "ocid1.image.oc1..aaaa", "lifecycleState": "AVAILABLE"}`))
+			return
+		}
+		if r.URL.Path != "/20160918/images/ocid1.image.oc1..aaaa/actions/export" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+
+		var details map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+			t.Errorf("Unexpected error decoding export details: %s", err)
+		}
+		expected := map[string]string{
+			"destinationType": "objectStorageTuple",
+			"bucketName":      "bucket",
+			"namespaceName":   "namespace",
+			"objectName":      "image.vmdk",
+			"exportFormat":    "VMDK",
+		}
+		if !reflect.DeepEqual(details, expected) {
+			t.Errorf("Expected export details %v, got %v", expected, details)
+		}
+
+		w.Write([]byte(`{"id": "ocid1.image.oc1..aaaa", "lifecycleState": "EXPORTING"}`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.ImageExportBucket = "bucket"
+	config.ImageExportNamespace = "namespace"
+	config.ImageExportName = "image.vmdk"
+	config.ImageExportFormat = "vmdk"
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	if _, err := d.ExportImage(context.Background(), "ocid1.image.oc1..aaaa"); err != nil {
+		t.Fatalf("Unexpected error exporting image: %s", err)
+	}
+}
+
+func TestDriverOCI_CopyImageCapabilitySchema(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/20160918/computeImageCapabilitySchemas/ocid1.computeimagecapabilityschema.oc1..schema":
+			w.Write([]byte(`{
+				"id": "ocid1.computeimagecapabilityschema.oc1..schema",
+				"computeGlobalImageCapabilitySchemaVersionName": "1.0",
+				"schemaData": {"Storage.BootVolumeType": {"descriptorType": "enumstring", "source": "IMAGE", "values": ["PARAVIRTUALIZED"], "defaultValue": "PARAVIRTUALIZED"}}
+			}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/20160918/computeImageCapabilitySchemas":
+			var details map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+				t.Errorf("Unexpected error decoding schema details: %s", err)
+			}
+			if details["imageId"] != "ocid1.image.oc1..aaaa" || details["computeGlobalImageCapabilitySchemaVersionName"] != "1.0" {
+				t.Errorf("Expected a schema for the image with the same version, got %v", details)
+			}
+			if _, ok := details["schemaData"].(map[string]interface{})["Storage.BootVolumeType"]; !ok {
+				t.Errorf("Expected the schema data to be copied, got %v", details["schemaData"])
+			}
+			w.Write([]byte(`{"id": "ocid1.computeimagecapabilityschema.oc1..copy"}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	driver, err := NewDriverOCI(baseTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	id, err := d.CopyImageCapabilitySchema(context.Background(), "ocid1.computeimagecapabilityschema.oc1..schema", "ocid1.image.oc1..aaaa")
+	if err != nil {
+		t.Fatalf("Unexpected error copying schema: %s", err)
+	}
+	if id != "ocid1.computeimagecapabilityschema.oc1..copy" {
+		t.Errorf("Expected the new schema's OCID, got %s", id)
+	}
+}
+
+func TestDriverOCI_SetImageOperatingSystem(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/20160918/images/ocid1.image.oc1..aaaa" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		var details map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+			t.Errorf("Unexpected error decoding image details: %s", err)
+		}
+		if details["operatingSystem"] != "Custom Linux" || details["operatingSystemVersion"] != "1.0" {
+			t.Errorf("Expected the operating system to be set, got %v", details)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.image.oc1..aaaa"}`))
+	}))
+	defer srv.Close()
+
+	driver, err := NewDriverOCI(baseTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	if err := d.SetImageOperatingSystem(context.Background(), "ocid1.image.oc1..aaaa", "Custom Linux", "1.0"); err != nil {
+		t.Fatalf("Unexpected error setting operating system: %s", err)
+	}
+}
+
+func TestDriverOCI_StopInstance(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/20160918/instances/ocid1.instance.oc1..aaaa" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		if action := r.URL.Query().Get("action"); action != "SOFTSTOP" {
+			t.Errorf("Expected a SOFTSTOP action, got %q", action)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.instance.oc1..aaaa", "lifecycleState": "STOPPING"}`))
+	}))
+	defer srv.Close()
+
+	driver, err := NewDriverOCI(baseTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	if err := d.StopInstance(context.Background(), "ocid1.instance.oc1..aaaa"); err != nil {
+		t.Fatalf("Unexpected error stopping instance: %s", err)
+	}
+}
+
+func TestDriverOCI_UpdateImageMergesTags(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/20160918/images/ocid1.image.oc1..aaaa" {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("etag", "etag-1")
+			w.Write([]byte(`{
+				"id": "ocid1.image.oc1..aaaa",
+				"freeformTags": {"Owner": "build", "Commit": "old"},
+				"definedTags": {"Operations": {"CostCenter": "42"}}
+			}`))
+		case http.MethodPut:
+			if etag := r.Header.Get("if-match"); etag != "etag-1" {
+				t.Errorf("Expected if-match of the image read, got %q", etag)
+			}
+
+			var details core.UpdateImageDetails
+			if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+				t.Errorf("Unexpected error decoding update details: %s", err)
+			}
+			expectedFreeform := map[string]string{"Owner": "build", "Commit": "abc123"}
+			if !reflect.DeepEqual(details.FreeformTags, expectedFreeform) {
+				t.Errorf("Expected freeform tags %v, got %v", expectedFreeform, details.FreeformTags)
+			}
+			expectedDefined := map[string]map[string]interface{}{
+				"Operations": {"CostCenter": "42", "Team": "images"},
+			}
+			if !reflect.DeepEqual(details.DefinedTags, expectedDefined) {
+				t.Errorf("Expected defined tags %v, got %v", expectedDefined, details.DefinedTags)
+			}
+
+			w.Write([]byte(`{"id": "ocid1.image.oc1..aaaa"}`))
+		default:
+			t.Errorf("Unexpected request method %s", r.Method)
+		}
+	}))
+	defer srv.Close()
+
+	driver, err := NewDriverOCI(baseTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	_, err = d.UpdateImage(context.Background(), "ocid1.image.oc1..aaaa",
+		map[string]string{"Commit": "abc123"},
+		map[string]map[string]interface{}{"Operations": {"Team": "images"}})
+	if err != nil {
+		t.Fatalf("Unexpected error updating image: %s", err)
+	}
+}
+
+func TestDriverOCI_AttachVnicOtherAvailabilityDomain(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/20160918/subnets/ocid1.subnet.oc1.phx.aaaa" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.subnet.oc1.phx.aaaa", "availabilityDomain": "aaaa:PHX-AD-2"}`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.AvailabilityDomain = "aaaa:PHX-AD-1"
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.vcnClient.Host = srv.URL
+	d.computeClient.Host = srv.URL
+
+	_, err = d.AttachVnic(context.Background(), "ocid1.instance.oc1..aaaa", "vnic", "ocid1.subnet.oc1.phx.aaaa", nil)
+	if err == nil || !strings.Contains(err.Error(), `is in availability domain "aaaa:PHX-AD-2"`) {
+		t.Fatalf("Expected an availability domain error, got %v", err)
+	}
+}
+
+func TestDriverOCI_AttachVolume(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/20160918/volumeAttachments":
+			var details map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+				t.Errorf("Unexpected error decoding attach details: %s", err)
+			}
+			if details["type"] != "iscsi" {
+				t.Errorf("Expected an iscsi attachment, got %v", details["type"])
+			}
+			w.Write([]byte(`{"attachmentType": "iscsi", "id": "ocid1.volumeattachment.1", "lifecycleState": "ATTACHING"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/20160918/volumeAttachments/ocid1.volumeattachment.1":
+			w.Write([]byte(`{"attachmentType": "iscsi", "id": "ocid1.volumeattachment.1", "lifecycleState": "ATTACHED"}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.StatePollInterval = time.Millisecond
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	id, err := d.AttachVolume(context.Background(), "ocid1.instance.oc1..aaaa", "ocid1.volume.oc1..aaaa", "iscsi")
+	if err != nil {
+		t.Fatalf("Unexpected error attaching volume: %s", err)
+	}
+	if id != "ocid1.volumeattachment.1" {
+		t.Errorf("Expected attachment ocid1.volumeattachment.1, got %s", id)
+	}
+}
+
+func TestDriverOCI_WaitForInstanceStateLaunchTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ocid1.instance.1", "lifecycleState": "PROVISIONING"}`))
+	}))
+	defer srv.Close()
+
+	config := baseTestConfig()
+	config.StatePollInterval = time.Millisecond
+	config.StateTimeout = time.Hour
+	config.InstanceLaunchTimeout = 10 * time.Millisecond
+
+	driver, err := NewDriverOCI(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating driver: %s", err)
+	}
+	d := driver.(*driverOCI)
+	d.computeClient.Host = srv.URL
+
+	_, err = d.WaitForInstanceState(context.Background(), "ocid1.instance.1", []string{"PROVISIONING"}, []string{"RUNNING"})
+	if err == nil || !strings.Contains(err.Error(), "Timed out after 10ms") {
+		t.Fatalf("Expected the wait to be bounded by instance_launch_timeout, got %v", err)
+	}
+}
@@ -0,0 +1,378 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+// identityClient is a minimal client for the few OCI Identity API operations
+// the builder needs, modelled on the clients generated for the OCI SDK. The
+// vendored SDK doesn't include its identity package. The driver's client
+// shares the driver's configuration provider.
+type identityClient struct {
+	common.BaseClient
+}
+
+// newIdentityClient creates an identityClient for the region of the given
+// configuration provider.
+func newIdentityClient(configProvider common.ConfigurationProvider) (identityClient, error) {
+	baseClient, err := common.NewClientWithConfig(configProvider)
+	if err != nil {
+		return identityClient{}, err
+	}
+
+	region, err := configProvider.Region()
+	if err != nil {
+		return identityClient{}, err
+	}
+
+	client := identityClient{BaseClient: baseClient}
+	client.BasePath = "20160918"
+	client.Host = common.StringToRegion(region).EndpointForTemplate("identity", "https://identity.{region}.{secondLevelDomain}")
+	return client, nil
+}
+
+// tagNamespaceSummary is a tag namespace as returned by ListTagNamespaces.
+type tagNamespaceSummary struct {
+	Id             *string `json:"id"`
+	CompartmentId  *string `json:"compartmentId"`
+	Name           *string `json:"name"`
+	LifecycleState string  `json:"lifecycleState"`
+}
+
+type listTagNamespacesRequest struct {
+	CompartmentId          *string `mandatory:"true" contributesTo:"query" name:"compartmentId"`
+	Page                   *string `mandatory:"false" contributesTo:"query" name:"page"`
+	IncludeSubcompartments *bool   `mandatory:"false" contributesTo:"query" name:"includeSubcompartments"`
+
+	RequestMetadata common.RequestMetadata
+}
+
+func (request listTagNamespacesRequest) HTTPRequest(method, path string) (http.Request, error) {
+	return common.MakeDefaultHTTPRequestWithTaggedStruct(method, path, request)
+}
+
+func (request listTagNamespacesRequest) RetryPolicy() *common.RetryPolicy {
+	return request.RequestMetadata.RetryPolicy
+}
+
+type listTagNamespacesResponse struct {
+	RawResponse *http.Response
+	Items       []tagNamespaceSummary `presentIn:"body"`
+	OpcNextPage *string               `presentIn:"header" name:"opc-next-page"`
+}
+
+func (response listTagNamespacesResponse) HTTPResponse() *http.Response {
+	return response.RawResponse
+}
+
+// ListTagNamespaces lists the tag namespaces in a compartment.
+func (client identityClient) ListTagNamespaces(ctx context.Context, request listTagNamespacesRequest) (listTagNamespacesResponse, error) {
+	policy := common.NoRetryPolicy()
+	if request.RetryPolicy() != nil {
+		policy = *request.RetryPolicy()
+	}
+
+	ociResponse, err := common.Retry(ctx, request, client.listTagNamespaces, policy)
+	if err != nil {
+		return listTagNamespacesResponse{}, err
+	}
+	response, ok := ociResponse.(listTagNamespacesResponse)
+	if !ok {
+		return listTagNamespacesResponse{}, fmt.Errorf("failed to convert OCIResponse into listTagNamespacesResponse")
+	}
+	return response, nil
+}
+
+func (client identityClient) listTagNamespaces(ctx context.Context, request common.OCIRequest) (common.OCIResponse, error) {
+	httpRequest, err := request.HTTPRequest(http.MethodGet, "/tagNamespaces")
+	if err != nil {
+		return nil, err
+	}
+
+	var response listTagNamespacesResponse
+	httpResponse, err := client.Call(ctx, &httpRequest)
+	defer common.CloseBodyIfValid(httpResponse)
+	response.RawResponse = httpResponse
+	if err != nil {
+		return response, err
+	}
+
+	err = common.UnmarshalResponse(httpResponse, &response)
+	return response, err
+}
+
+// compartmentSummary is a compartment as returned by ListCompartments.
+type compartmentSummary struct {
+	Id             *string `json:"id"`
+	CompartmentId  *string `json:"compartmentId"`
+	Name           *string `json:"name"`
+	LifecycleState string  `json:"lifecycleState"`
+}
+
+type listCompartmentsRequest struct {
+	CompartmentId          *string `mandatory:"true" contributesTo:"query" name:"compartmentId"`
+	Page                   *string `mandatory:"false" contributesTo:"query" name:"page"`
+	AccessLevel            *string `mandatory:"false" contributesTo:"query" name:"accessLevel"`
+	CompartmentIdInSubtree *bool   `mandatory:"false" contributesTo:"query" name:"compartmentIdInSubtree"`
+	Name                   *string `mandatory:"false" contributesTo:"query" name:"name"`
+
+	RequestMetadata common.RequestMetadata
+}
+
+func (request listCompartmentsRequest) HTTPRequest(method, path string) (http.Request, error) {
+	return common.MakeDefaultHTTPRequestWithTaggedStruct(method, path, request)
+}
+
+func (request listCompartmentsRequest) RetryPolicy() *common.RetryPolicy {
+	return request.RequestMetadata.RetryPolicy
+}
+
+type listCompartmentsResponse struct {
+	RawResponse *http.Response
+	Items       []compartmentSummary `presentIn:"body"`
+	OpcNextPage *string              `presentIn:"header" name:"opc-next-page"`
+}
+
+func (response listCompartmentsResponse) HTTPResponse() *http.Response {
+	return response.RawResponse
+}
+
+// ListCompartments lists the compartments in a compartment.
+func (client identityClient) ListCompartments(ctx context.Context, request listCompartmentsRequest) (listCompartmentsResponse, error) {
+	policy := common.NoRetryPolicy()
+	if request.RetryPolicy() != nil {
+		policy = *request.RetryPolicy()
+	}
+
+	ociResponse, err := common.Retry(ctx, request, client.listCompartments, policy)
+	if err != nil {
+		return listCompartmentsResponse{}, err
+	}
+	response, ok := ociResponse.(listCompartmentsResponse)
+	if !ok {
+		return listCompartmentsResponse{}, fmt.Errorf("failed to convert OCIResponse into listCompartmentsResponse")
+	}
+	return response, nil
+}
+
+func (client identityClient) listCompartments(ctx context.Context, request common.OCIRequest) (common.OCIResponse, error) {
+	httpRequest, err := request.HTTPRequest(http.MethodGet, "/compartments")
+	if err != nil {
+		return nil, err
+	}
+
+	var response listCompartmentsResponse
+	httpResponse, err := client.Call(ctx, &httpRequest)
+	defer common.CloseBodyIfValid(httpResponse)
+	response.RawResponse = httpResponse
+	if err != nil {
+		return response, err
+	}
+
+	err = common.UnmarshalResponse(httpResponse, &response)
+	return response, err
+}
+
+// availabilityDomain is an availability domain as returned by
+// ListAvailabilityDomains.
+type availabilityDomain struct {
+	Id            *string `json:"id"`
+	Name          *string `json:"name"`
+	CompartmentId *string `json:"compartmentId"`
+}
+
+type listAvailabilityDomainsRequest struct {
+	CompartmentId *string `mandatory:"true" contributesTo:"query" name:"compartmentId"`
+
+	RequestMetadata common.RequestMetadata
+}
+
+func (request listAvailabilityDomainsRequest) HTTPRequest(method, path string) (http.Request, error) {
+	return common.MakeDefaultHTTPRequestWithTaggedStruct(method, path, request)
+}
+
+func (request listAvailabilityDomainsRequest) RetryPolicy() *common.RetryPolicy {
+	return request.RequestMetadata.RetryPolicy
+}
+
+type listAvailabilityDomainsResponse struct {
+	RawResponse *http.Response
+	Items       []availabilityDomain `presentIn:"body"`
+}
+
+func (response listAvailabilityDomainsResponse) HTTPResponse() *http.Response {
+	return response.RawResponse
+}
+
+// ListAvailabilityDomains lists the availability domains of the region that
+// a compartment can use.
+func (client identityClient) ListAvailabilityDomains(ctx context.Context, request listAvailabilityDomainsRequest) (listAvailabilityDomainsResponse, error) {
+	policy := common.NoRetryPolicy()
+	if request.RetryPolicy() != nil {
+		policy = *request.RetryPolicy()
+	}
+
+	ociResponse, err := common.Retry(ctx, request, client.listAvailabilityDomains, policy)
+	if err != nil {
+		return listAvailabilityDomainsResponse{}, err
+	}
+	response, ok := ociResponse.(listAvailabilityDomainsResponse)
+	if !ok {
+		return listAvailabilityDomainsResponse{}, fmt.Errorf("failed to convert OCIResponse into listAvailabilityDomainsResponse")
+	}
+	return response, nil
+}
+
+func (client identityClient) listAvailabilityDomains(ctx context.Context, request common.OCIRequest) (common.OCIResponse, error) {
+	httpRequest, err := request.HTTPRequest(http.MethodGet, "/availabilityDomains")
+	if err != nil {
+		return nil, err
+	}
+
+	var response listAvailabilityDomainsResponse
+	httpResponse, err := client.Call(ctx, &httpRequest)
+	defer common.CloseBodyIfValid(httpResponse)
+	response.RawResponse = httpResponse
+	if err != nil {
+		return response, err
+	}
+
+	err = common.UnmarshalResponse(httpResponse, &response)
+	return response, err
+}
+
+// tagDefaultSummary is a tag default as returned by ListTagDefaults.
+type tagDefaultSummary struct {
+	Id                *string `json:"id"`
+	CompartmentId     *string `json:"compartmentId"`
+	TagNamespaceId    *string `json:"tagNamespaceId"`
+	TagDefinitionId   *string `json:"tagDefinitionId"`
+	TagDefinitionName *string `json:"tagDefinitionName"`
+	Value             *string `json:"value"`
+	LifecycleState    string  `json:"lifecycleState"`
+}
+
+type listTagDefaultsRequest struct {
+	CompartmentId *string `mandatory:"false" contributesTo:"query" name:"compartmentId"`
+	Page          *string `mandatory:"false" contributesTo:"query" name:"page"`
+
+	RequestMetadata common.RequestMetadata
+}
+
+func (request listTagDefaultsRequest) HTTPRequest(method, path string) (http.Request, error) {
+	return common.MakeDefaultHTTPRequestWithTaggedStruct(method, path, request)
+}
+
+func (request listTagDefaultsRequest) RetryPolicy() *common.RetryPolicy {
+	return request.RequestMetadata.RetryPolicy
+}
+
+type listTagDefaultsResponse struct {
+	RawResponse *http.Response
+	Items       []tagDefaultSummary `presentIn:"body"`
+	OpcNextPage *string             `presentIn:"header" name:"opc-next-page"`
+}
+
+func (response listTagDefaultsResponse) HTTPResponse() *http.Response {
+	return response.RawResponse
+}
+
+// ListTagDefaults lists the tag defaults of a compartment.
+func (client identityClient) ListTagDefaults(ctx context.Context, request listTagDefaultsRequest) (listTagDefaultsResponse, error) {
+	policy := common.NoRetryPolicy()
+	if request.RetryPolicy() != nil {
+		policy = *request.RetryPolicy()
+	}
+
+	ociResponse, err := common.Retry(ctx, request, client.listTagDefaults, policy)
+	if err != nil {
+		return listTagDefaultsResponse{}, err
+	}
+	response, ok := ociResponse.(listTagDefaultsResponse)
+	if !ok {
+		return listTagDefaultsResponse{}, fmt.Errorf("failed to convert OCIResponse into listTagDefaultsResponse")
+	}
+	return response, nil
+}
+
+func (client identityClient) listTagDefaults(ctx context.Context, request common.OCIRequest) (common.OCIResponse, error) {
+	httpRequest, err := request.HTTPRequest(http.MethodGet, "/tagDefaults")
+	if err != nil {
+		return nil, err
+	}
+
+	var response listTagDefaultsResponse
+	httpResponse, err := client.Call(ctx, &httpRequest)
+	defer common.CloseBodyIfValid(httpResponse)
+	response.RawResponse = httpResponse
+	if err != nil {
+		return response, err
+	}
+
+	err = common.UnmarshalResponse(httpResponse, &response)
+	return response, err
+}
+
+type getTagNamespaceRequest struct {
+	TagNamespaceId *string `mandatory:"true" contributesTo:"path" name:"tagNamespaceId"`
+
+	RequestMetadata common.RequestMetadata
+}
+
+func (request getTagNamespaceRequest) HTTPRequest(method, path string) (http.Request, error) {
+	return common.MakeDefaultHTTPRequestWithTaggedStruct(method, path, request)
+}
+
+func (request getTagNamespaceRequest) RetryPolicy() *common.RetryPolicy {
+	return request.RequestMetadata.RetryPolicy
+}
+
+type getTagNamespaceResponse struct {
+	RawResponse  *http.Response
+	TagNamespace tagNamespaceSummary `presentIn:"body"`
+}
+
+func (response getTagNamespaceResponse) HTTPResponse() *http.Response {
+	return response.RawResponse
+}
+
+// GetTagNamespace gets a tag namespace, which tag defaults only refer to by
+// OCID.
+func (client identityClient) GetTagNamespace(ctx context.Context, request getTagNamespaceRequest) (getTagNamespaceResponse, error) {
+	policy := common.NoRetryPolicy()
+	if request.RetryPolicy() != nil {
+		policy = *request.RetryPolicy()
+	}
+
+	ociResponse, err := common.Retry(ctx, request, client.getTagNamespace, policy)
+	if err != nil {
+		return getTagNamespaceResponse{}, err
+	}
+	response, ok := ociResponse.(getTagNamespaceResponse)
+	if !ok {
+		return getTagNamespaceResponse{}, fmt.Errorf("failed to convert OCIResponse into getTagNamespaceResponse")
+	}
+	return response, nil
+}
+
+func (client identityClient) getTagNamespace(ctx context.Context, request common.OCIRequest) (common.OCIResponse, error) {
+	httpRequest, err := request.HTTPRequest(http.MethodGet, "/tagNamespaces/{tagNamespaceId}")
+	if err != nil {
+		return nil, err
+	}
+
+	var response getTagNamespaceResponse
+	httpResponse, err := client.Call(ctx, &httpRequest)
+	defer common.CloseBodyIfValid(httpResponse)
+	response.RawResponse = httpResponse
+	if err != nil {
+		return response, err
+	}
+
+	err = common.UnmarshalResponse(httpResponse, &response)
+	return response, err
+}
@@ -0,0 +1,206 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// launchInstanceRequest extends core.LaunchInstanceRequest with launch
+// details the vendored SDK predates. They are merged into the JSON body the
+// SDK marshals for the embedded request.
+type launchInstanceRequest struct {
+	core.LaunchInstanceRequest
+
+	AgentConfig               *launchInstanceAgentConfig
+	CapacityReservationId     *string
+	InstanceOptions           *instanceOptions
+	PlatformConfig            *platformConfig
+	PreemptibleInstanceConfig *preemptibleInstanceConfig
+	ShapeConfig               *launchInstanceShapeConfig
+}
+
+// launchInstanceAgentConfig configures the Oracle Cloud Agent, including the
+// plugin settings that core.LaunchInstanceAgentConfigDetails lacks.
+type launchInstanceAgentConfig struct {
+	AreAllPluginsDisabled *bool                       `json:"areAllPluginsDisabled,omitempty"`
+	PluginsConfig         []instanceAgentPluginConfig `json:"pluginsConfig,omitempty"`
+}
+
+type instanceAgentPluginConfig struct {
+	Name         string `json:"name"`
+	DesiredState string `json:"desiredState"`
+}
+
+// instanceOptions are the instance's metadata service options.
+type instanceOptions struct {
+	AreLegacyImdsEndpointsDisabled *bool `json:"areLegacyImdsEndpointsDisabled,omitempty"`
+}
+
+// platformConfig enables the shielded instance features of a shape's
+// platform, identified by Type.
+type platformConfig struct {
+	Type                           string `json:"type"`
+	IsSecureBootEnabled            *bool  `json:"isSecureBootEnabled,omitempty"`
+	IsMeasuredBootEnabled          *bool  `json:"isMeasuredBootEnabled,omitempty"`
+	IsTrustedPlatformModuleEnabled *bool  `json:"isTrustedPlatformModuleEnabled,omitempty"`
+}
+
+// launchInstanceShapeConfig sizes a flexible shape, including the memory that
+// core.LaunchInstanceShapeConfigDetails lacks.
+type launchInstanceShapeConfig struct {
+	Ocpus       *float32 `json:"ocpus,omitempty"`
+	MemoryInGBs *float32 `json:"memoryInGBs,omitempty"`
+}
+
+// preemptibleInstanceConfig launches a preemptible instance, with the action
+// taken when it is preempted.
+type preemptibleInstanceConfig struct {
+	PreemptionAction preemptionAction `json:"preemptionAction"`
+}
+
+type preemptionAction struct {
+	Type               string `json:"type"`
+	PreserveBootVolume *bool  `json:"preserveBootVolume,omitempty"`
+}
+
+// instanceSourceViaImageDetails extends core.InstanceSourceViaImageDetails
+// with the boot volume performance, which the vendored SDK predates.
+type instanceSourceViaImageDetails struct {
+	core.InstanceSourceViaImageDetails
+
+	BootVolumeVpusPerGB *int64
+}
+
+func (m instanceSourceViaImageDetails) MarshalJSON() ([]byte, error) {
+	if m.BootVolumeVpusPerGB == nil {
+		return json.Marshal(m.InstanceSourceViaImageDetails)
+	}
+	return marshalWithFields(m.InstanceSourceViaImageDetails, map[string]interface{}{
+		"bootVolumeVpusPerGB": *m.BootVolumeVpusPerGB,
+	})
+}
+
+// marshalWithFields marshals v, an SDK model, to a JSON object with fields
+// added that the vendored SDK predates.
+func marshalWithFields(v interface{}, fields map[string]interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode numbers as json.Number so they are re-encoded unchanged.
+	var details map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&details); err != nil {
+		return nil, err
+	}
+	for key, value := range fields {
+		details[key] = value
+	}
+	return json.Marshal(details)
+}
+
+func (request launchInstanceRequest) HTTPRequest(method, path string) (http.Request, error) {
+	extra := map[string]interface{}{}
+	if request.AgentConfig != nil {
+		extra["agentConfig"] = request.AgentConfig
+	}
+	if request.CapacityReservationId != nil {
+		extra["capacityReservationId"] = *request.CapacityReservationId
+	}
+	if request.InstanceOptions != nil {
+		extra["instanceOptions"] = request.InstanceOptions
+	}
+	if request.PlatformConfig != nil {
+		extra["platformConfig"] = request.PlatformConfig
+	}
+	if request.PreemptibleInstanceConfig != nil {
+		extra["preemptibleInstanceConfig"] = request.PreemptibleInstanceConfig
+	}
+	if request.ShapeConfig != nil {
+		extra["shapeConfig"] = request.ShapeConfig
+	}
+
+	httpRequest, err := request.LaunchInstanceRequest.HTTPRequest(method, path)
+	if err != nil || len(extra) == 0 {
+		return httpRequest, err
+	}
+
+	raw, err := ioutil.ReadAll(httpRequest.Body)
+	if err != nil {
+		return httpRequest, err
+	}
+
+	// Decode numbers as json.Number so they are re-encoded unchanged.
+	var details map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&details); err != nil {
+		return httpRequest, err
+	}
+	for key, value := range extra {
+		details[key] = value
+	}
+
+	body, err := json.Marshal(details)
+	if err != nil {
+		return httpRequest, err
+	}
+
+	httpRequest.ContentLength = int64(len(body))
+	httpRequest.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	httpRequest.Body = ioutil.NopCloser(bytes.NewReader(body))
+	httpRequest.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	return httpRequest, nil
+}
+
+// launchInstance launches an instance like ComputeClient.LaunchInstance but
+// from a launchInstanceRequest.
+func launchInstance(ctx context.Context, client core.ComputeClient, request launchInstanceRequest) (core.LaunchInstanceResponse, error) {
+	policy := common.NoRetryPolicy()
+	if request.RetryPolicy() != nil {
+		policy = *request.RetryPolicy()
+	}
+
+	if request.OpcRetryToken == nil || *request.OpcRetryToken == "" {
+		request.OpcRetryToken = common.String(common.RetryToken())
+	}
+
+	ociResponse, err := common.Retry(ctx, request, func(ctx context.Context, request common.OCIRequest) (common.OCIResponse, error) {
+		httpRequest, err := request.HTTPRequest(http.MethodPost, "/instances")
+		if err != nil {
+			return nil, err
+		}
+
+		var response core.LaunchInstanceResponse
+		httpResponse, err := client.Call(ctx, &httpRequest)
+		defer common.CloseBodyIfValid(httpResponse)
+		response.RawResponse = httpResponse
+		if err != nil {
+			return response, err
+		}
+
+		err = common.UnmarshalResponse(httpResponse, &response)
+		return response, err
+	}, policy)
+	if err != nil {
+		return core.LaunchInstanceResponse{}, err
+	}
+	response, ok := ociResponse.(core.LaunchInstanceResponse)
+	if !ok {
+		return core.LaunchInstanceResponse{}, fmt.Errorf("failed to convert OCIResponse into LaunchInstanceResponse")
+	}
+	return response, nil
+}
@@ -0,0 +1,63 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// stepAttachVnics attaches the secondary_vnics to the instance, and detaches
+// them again before the instance is terminated.
+type stepAttachVnics struct{}
+
+func (s *stepAttachVnics) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	var (
+		driver     = state.Get("driver").(Driver)
+		ui         = state.Get("ui").(packersdk.Ui)
+		config     = state.Get("config").(*Config)
+		instanceID = state.Get("instance_id").(string)
+	)
+
+	for i, vnic := range config.SecondaryVnics {
+		name := fmt.Sprintf("%s-vnic-%d", config.ImageName, i)
+		ui.Say(fmt.Sprintf("Attaching secondary VNIC %s in subnet (%s)...", name, vnic.SubnetID))
+
+		attachmentID, err := driver.AttachVnic(ctx, instanceID, name, vnic.SubnetID, vnic.NsgIDs)
+		if err != nil {
+			err = fmt.Errorf("Error attaching secondary VNIC: %s", err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+		state.Put("vnic_attachment_ids", append(stateStrings(state, "vnic_attachment_ids"), attachmentID))
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepAttachVnics) Cleanup(state multistep.StateBag) {
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packersdk.Ui)
+
+	attachmentIDs := stateStrings(state, "vnic_attachment_ids")
+	if len(attachmentIDs) == 0 {
+		return
+	}
+
+	if keepInstance(state) {
+		ui.Say(fmt.Sprintf("Keeping secondary VNIC attachments %v.", attachmentIDs))
+		return
+	}
+
+	for _, id := range attachmentIDs {
+		ui.Say(fmt.Sprintf("Detaching secondary VNIC attachment (%s)...", id))
+		if err := driver.DetachVnic(context.TODO(), id); err != nil {
+			err = fmt.Errorf("Error detaching secondary VNIC. Please detach it manually: %s", err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return
+		}
+	}
+}
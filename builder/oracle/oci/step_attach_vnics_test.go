@@ -0,0 +1,58 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+)
+
+func TestStepAttachVnics(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.ImageName = "image"
+	config.SecondaryVnics = []SecondaryVnic{
+		{SubnetID: "ocid1.subnet.oc1.phx.aaaa"},
+		{SubnetID: "ocid1.subnet.oc1.phx.bbbb", NsgIDs: []string{"ocid1.networksecuritygroup.oc1.phx.aaaa"}},
+	}
+
+	step := new(stepAttachVnics)
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	expected := []string{"ocid1.vnicattachment.image-vnic-0", "ocid1.vnicattachment.image-vnic-1"}
+	if !reflect.DeepEqual(driver.AttachVnicIDs, expected) {
+		t.Fatalf("Expected VNICs %v to be attached, got %v", expected, driver.AttachVnicIDs)
+	}
+
+	step.Cleanup(state)
+
+	if !reflect.DeepEqual(driver.DetachVnicIDs, expected) {
+		t.Fatalf("Expected attachments %v to be detached, got %v", expected, driver.DetachVnicIDs)
+	}
+}
+
+func TestStepAttachVnics_AttachVnicErr(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.SecondaryVnics = []SecondaryVnic{{SubnetID: "ocid1.subnet.oc1.phx.aaaa"}}
+
+	step := new(stepAttachVnics)
+	driver := state.Get("driver").(*driverMock)
+	driver.AttachVnicErr = errors.New("error")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+}
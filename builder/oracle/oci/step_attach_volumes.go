@@ -0,0 +1,91 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// stepAttachVolumes creates the block_volumes and attaches them to the
+// instance for the provisioners to use.
+type stepAttachVolumes struct{}
+
+func (s *stepAttachVolumes) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	var (
+		driver     = state.Get("driver").(Driver)
+		ui         = state.Get("ui").(packersdk.Ui)
+		config     = state.Get("config").(*Config)
+		instanceID = state.Get("instance_id").(string)
+	)
+
+	for i, volume := range config.BlockVolumes {
+		name := fmt.Sprintf("%s-volume-%d", config.ImageName, i)
+		ui.Say(fmt.Sprintf("Creating %dGB block volume %s...", volume.SizeInGBs, name))
+
+		volumeID, err := driver.CreateVolume(ctx, name, volume.SizeInGBs, volume.FreeformTags, volume.DefinedTags)
+		if err != nil {
+			err = fmt.Errorf("Error creating block volume: %s", err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+		state.Put("volume_ids", append(stateStrings(state, "volume_ids"), volumeID))
+
+		ui.Say(fmt.Sprintf("Attaching block volume (%s)...", volumeID))
+
+		attachmentID, err := driver.AttachVolume(ctx, instanceID, volumeID, volume.AttachmentType)
+		if err != nil {
+			err = fmt.Errorf("Error attaching block volume: %s", err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+		state.Put("volume_attachment_ids", append(stateStrings(state, "volume_attachment_ids"), attachmentID))
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepAttachVolumes) Cleanup(state multistep.StateBag) {
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packersdk.Ui)
+
+	volumeIDs := stateStrings(state, "volume_ids")
+	if len(volumeIDs) == 0 {
+		return
+	}
+
+	if keepInstance(state) {
+		ui.Say(fmt.Sprintf("Keeping block volumes %v attached to the instance. Please delete them manually.", volumeIDs))
+		return
+	}
+
+	for _, id := range stateStrings(state, "volume_attachment_ids") {
+		ui.Say(fmt.Sprintf("Detaching block volume attachment (%s)...", id))
+		if err := driver.DetachVolume(context.TODO(), id); err != nil {
+			err = fmt.Errorf("Error detaching block volume. Please detach and delete it manually: %s", err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return
+		}
+	}
+
+	for _, id := range volumeIDs {
+		ui.Say(fmt.Sprintf("Deleting block volume (%s)...", id))
+		if err := driver.DeleteVolume(context.TODO(), id); err != nil {
+			err = fmt.Errorf("Error deleting block volume. Please delete it manually: %s", err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+		}
+	}
+}
+
+// stateStrings returns the []string in the state bag under key, if any.
+func stateStrings(state multistep.StateBag, key string) []string {
+	if raw, ok := state.GetOk(key); ok {
+		return raw.([]string)
+	}
+	return nil
+}
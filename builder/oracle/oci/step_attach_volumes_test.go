@@ -0,0 +1,92 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+)
+
+func TestStepAttachVolumes(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.ImageName = "image"
+	config.BlockVolumes = []BlockVolume{
+		{SizeInGBs: 50, AttachmentType: "paravirtualized"},
+		{SizeInGBs: 100, AttachmentType: "iscsi"},
+	}
+
+	step := new(stepAttachVolumes)
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	expectedVolumes := []string{"image-volume-0", "image-volume-1"}
+	if !reflect.DeepEqual(driver.CreateVolumeIDs, expectedVolumes) {
+		t.Fatalf("Expected volumes %v to be created, got %v", expectedVolumes, driver.CreateVolumeIDs)
+	}
+	if len(driver.AttachVolumeIDs) != 2 {
+		t.Fatalf("Expected both volumes to be attached, got %v", driver.AttachVolumeIDs)
+	}
+
+	step.Cleanup(state)
+
+	if !reflect.DeepEqual(driver.DetachVolumeIDs, driver.AttachVolumeIDs) {
+		t.Fatalf("Expected attachments %v to be detached, got %v", driver.AttachVolumeIDs, driver.DetachVolumeIDs)
+	}
+	if !reflect.DeepEqual(driver.DeleteVolumeIDs, expectedVolumes) {
+		t.Fatalf("Expected volumes %v to be deleted, got %v", expectedVolumes, driver.DeleteVolumeIDs)
+	}
+}
+
+func TestStepAttachVolumes_AttachVolumeErr(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.BlockVolumes = []BlockVolume{{SizeInGBs: 50, AttachmentType: "paravirtualized"}}
+
+	step := new(stepAttachVolumes)
+	driver := state.Get("driver").(*driverMock)
+	driver.AttachVolumeErr = errors.New("error")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+
+	step.Cleanup(state)
+
+	if len(driver.DeleteVolumeIDs) != 1 {
+		t.Fatalf("Should have deleted the volume that failed to attach, got %v", driver.DeleteVolumeIDs)
+	}
+}
+
+func TestStepAttachVolumes_DebugKeepInstance(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.DebugKeepInstance = true
+	config.BlockVolumes = []BlockVolume{{SizeInGBs: 50, AttachmentType: "paravirtualized"}}
+
+	step := new(stepAttachVolumes)
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	state.Put(multistep.StateHalted, true)
+	step.Cleanup(state)
+
+	if len(driver.DetachVolumeIDs) != 0 || len(driver.DeleteVolumeIDs) != 0 {
+		t.Fatalf("Should have kept the volumes attached to the kept instance")
+	}
+}
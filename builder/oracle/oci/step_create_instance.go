@@ -2,13 +2,18 @@ package oci
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/packerbuilderdata"
 )
 
-type stepCreateInstance struct{}
+type stepCreateInstance struct {
+	GeneratedData *packerbuilderdata.GeneratedData
+}
 
 func (s *stepCreateInstance) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	var (
@@ -17,9 +22,105 @@ func (s *stepCreateInstance) Run(ctx context.Context, state multistep.StateBag)
 		config = state.Get("config").(*Config)
 	)
 
+	if config.UseInstanceID != "" {
+		ui.Say(fmt.Sprintf("Using existing instance (%s)...", config.UseInstanceID))
+
+		_, err := driver.WaitForInstanceState(ctx, config.UseInstanceID, []string{"STARTING", "PROVISIONING"}, []string{"RUNNING"})
+		if err != nil {
+			err = fmt.Errorf("Error waiting for existing instance to be running: %s", err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+
+		state.Put("instance_id", config.UseInstanceID)
+		s.GeneratedData.Put("InstanceID", config.UseInstanceID)
+		return multistep.ActionContinue
+	}
+
+	if config.BaseImageID == "" && config.SourceBootVolumeID == "" {
+		ui.Say("Resolving base image from base_image_filter...")
+
+		images, err := driver.ListImages(ctx)
+		if err != nil {
+			err = fmt.Errorf("Problem listing base images: %s", err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+		if len(images) == 0 {
+			err = errors.New("Problem resolving base image: base_image_filter matched no images")
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+
+		config.BaseImageID = *images[0].Id
+		ui.Say(fmt.Sprintf("Using base image (%s).", config.BaseImageID))
+	}
+
+	if config.SubnetName != "" {
+		ui.Say(fmt.Sprintf("Resolving subnet %q in VCN (%s)...", config.SubnetName, config.VcnID))
+
+		subnets, err := driver.ListSubnets(ctx)
+		if err != nil {
+			err = fmt.Errorf("Problem listing subnets: %s", err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+		if len(subnets) != 1 {
+			if len(subnets) == 0 {
+				err = fmt.Errorf("Problem resolving subnet: subnet_name %q matched no available subnets usable in %s",
+					config.SubnetName, config.AvailabilityDomain)
+			} else {
+				ids := make([]string, len(subnets))
+				for i, subnet := range subnets {
+					ids[i] = *subnet.Id
+				}
+				err = fmt.Errorf("Problem resolving subnet: subnet_name %q matched %d subnets: %s",
+					config.SubnetName, len(subnets), strings.Join(ids, ", "))
+			}
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+
+		// create_vnic_details' subnet points at subnet_ocid.
+		config.SubnetID = *subnets[0].Id
+		ui.Say(fmt.Sprintf("Using subnet (%s).", config.SubnetID))
+	}
+
+	if config.DedicatedVmHostID != "" {
+		// A mismatch makes the launch fail, but the host may only be
+		// readable with wider permissions than launching onto it needs, so
+		// only warn and let the launch report the real error.
+		host, err := driver.GetDedicatedVmHost(ctx, config.DedicatedVmHostID)
+		if err != nil {
+			ui.Message(fmt.Sprintf(
+				"Warning: unable to check the availability domain of dedicated VM host (%s): %s",
+				config.DedicatedVmHostID, err))
+		} else if host.AvailabilityDomain != nil && *host.AvailabilityDomain != config.AvailabilityDomain {
+			ui.Message(fmt.Sprintf(
+				"Warning: dedicated VM host (%s) is in availability domain %q but availability_domain is %q.",
+				config.DedicatedVmHostID, *host.AvailabilityDomain, config.AvailabilityDomain))
+		}
+	}
+
+	if config.Preemptible {
+		ui.Message("Warning: the instance is preemptible. If OCI reclaims the capacity the instance is " +
+			"terminated and the build fails, even part way through provisioning.")
+	}
+
 	ui.Say("Creating instance...")
 
-	instanceID, err := driver.CreateInstance(ctx, string(config.Comm.SSHPublicKey))
+	// WinRM uses the instance's initial credentials rather than a key.
+	publicKey := string(config.Comm.SSHPublicKey)
+	if config.Comm.Type == "winrm" {
+		publicKey = ""
+	}
+
+	instanceID, err := driver.CreateInstance(ctx, publicKey)
 	if err != nil {
 		err = fmt.Errorf("Problem creating instance: %s", err)
 		ui.Error(err.Error())
@@ -28,12 +129,13 @@ func (s *stepCreateInstance) Run(ctx context.Context, state multistep.StateBag)
 	}
 
 	state.Put("instance_id", instanceID)
+	s.GeneratedData.Put("InstanceID", instanceID)
 
 	ui.Say(fmt.Sprintf("Created instance (%s).", instanceID))
 
 	ui.Say("Waiting for instance to enter 'RUNNING' state...")
 
-	if err = driver.WaitForInstanceState(ctx, instanceID, []string{"STARTING", "PROVISIONING"}, "RUNNING"); err != nil {
+	if _, err = driver.WaitForInstanceState(ctx, instanceID, []string{"STARTING", "PROVISIONING"}, []string{"RUNNING"}); err != nil {
 		err = fmt.Errorf("Error waiting for instance to start: %s", err)
 		ui.Error(err.Error())
 		state.Put("error", err)
@@ -48,6 +150,7 @@ func (s *stepCreateInstance) Run(ctx context.Context, state multistep.StateBag)
 func (s *stepCreateInstance) Cleanup(state multistep.StateBag) {
 	driver := state.Get("driver").(Driver)
 	ui := state.Get("ui").(packersdk.Ui)
+	config := state.Get("config").(*Config)
 
 	idRaw, ok := state.GetOk("instance_id")
 	if !ok {
@@ -55,6 +158,20 @@ func (s *stepCreateInstance) Cleanup(state multistep.StateBag) {
 	}
 	id := idRaw.(string)
 
+	if config.UseInstanceID != "" {
+		ui.Say(fmt.Sprintf("Leaving existing instance (%s) running.", id))
+		return
+	}
+
+	if keepInstance(state) {
+		message := fmt.Sprintf("Keeping instance (%s) for debugging. Please terminate it manually.", id)
+		if ip, ok := state.GetOk("instance_ip"); ok {
+			message = fmt.Sprintf("Keeping instance (%s) with IP %s for debugging. Please terminate it manually.", id, ip)
+		}
+		ui.Say(message)
+		return
+	}
+
 	ui.Say(fmt.Sprintf("Terminating instance (%s)...", id))
 
 	if err := driver.TerminateInstance(context.TODO(), id); err != nil {
@@ -64,7 +181,14 @@ func (s *stepCreateInstance) Cleanup(state multistep.StateBag) {
 		return
 	}
 
-	err := driver.WaitForInstanceState(context.TODO(), id, []string{"TERMINATING"}, "TERMINATED")
+	if !config.WaitForTermination {
+		ui.Say("Requested termination of instance.")
+		return
+	}
+
+	// An instance that was stopped, or is being stopped, passes through
+	// STOPPING and STOPPED on its way to TERMINATED.
+	_, err := driver.WaitForInstanceState(context.TODO(), id, []string{"STOPPING", "STOPPED", "TERMINATING"}, []string{"TERMINATED"})
 	if err != nil {
 		err = fmt.Errorf("Error terminating instance. Please terminate manually: %s", err)
 		ui.Error(err.Error())
@@ -74,3 +198,13 @@ func (s *stepCreateInstance) Cleanup(state multistep.StateBag) {
 
 	ui.Say("Terminated instance.")
 }
+
+// keepInstance reports whether debug_keep_instance means that the instance,
+// and the volumes attached to it, are left for inspection rather than cleaned
+// up. That is when the build failed or skip_create_image is set.
+func keepInstance(state multistep.StateBag) bool {
+	config := state.Get("config").(*Config)
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+	return config.DebugKeepInstance && (cancelled || halted || config.SkipCreateImage)
+}
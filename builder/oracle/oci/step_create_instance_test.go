@@ -1,18 +1,23 @@
 package oci
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/packerbuilderdata"
+	"github.com/oracle/oci-go-sdk/core"
 )
 
 func TestStepCreateInstance(t *testing.T) {
 	state := testState()
 	state.Put("publicKey", "key")
 
-	step := new(stepCreateInstance)
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
 	defer step.Cleanup(state)
 
 	driver := state.Get("driver").(*driverMock)
@@ -26,6 +31,11 @@ func TestStepCreateInstance(t *testing.T) {
 		t.Fatalf("should have machine")
 	}
 
+	generatedData := state.Get("generated_data").(map[string]interface{})
+	if generatedData["InstanceID"] != instanceIDRaw {
+		t.Fatalf("should've exposed the instance id as generated data, got %v", generatedData["InstanceID"])
+	}
+
 	step.Cleanup(state)
 
 	if driver.TerminateInstanceID != instanceIDRaw.(string) {
@@ -39,7 +49,7 @@ func TestStepCreateInstance_CreateInstanceErr(t *testing.T) {
 	state := testState()
 	state.Put("publicKey", "key")
 
-	step := new(stepCreateInstance)
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
 	defer step.Cleanup(state)
 
 	driver := state.Get("driver").(*driverMock)
@@ -68,7 +78,7 @@ func TestStepCreateInstance_WaitForInstanceStateErr(t *testing.T) {
 	state := testState()
 	state.Put("publicKey", "key")
 
-	step := new(stepCreateInstance)
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
 	defer step.Cleanup(state)
 
 	driver := state.Get("driver").(*driverMock)
@@ -87,7 +97,7 @@ func TestStepCreateInstance_TerminateInstanceErr(t *testing.T) {
 	state := testState()
 	state.Put("publicKey", "key")
 
-	step := new(stepCreateInstance)
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
 	defer step.Cleanup(state)
 
 	driver := state.Get("driver").(*driverMock)
@@ -113,7 +123,7 @@ func TestStepCreateInstanceCleanup_WaitForInstanceStateErr(t *testing.T) {
 	state := testState()
 	state.Put("publicKey", "key")
 
-	step := new(stepCreateInstance)
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
 	defer step.Cleanup(state)
 
 	driver := state.Get("driver").(*driverMock)
@@ -122,6 +132,9 @@ func TestStepCreateInstanceCleanup_WaitForInstanceStateErr(t *testing.T) {
 		t.Fatalf("bad action: %#v", action)
 	}
 
+	config := state.Get("config").(*Config)
+	config.WaitForTermination = true
+
 	driver.WaitForInstanceStateErr = errors.New("error")
 	step.Cleanup(state)
 
@@ -129,3 +142,273 @@ func TestStepCreateInstanceCleanup_WaitForInstanceStateErr(t *testing.T) {
 		t.Fatalf("should have error")
 	}
 }
+
+func TestStepCreateInstanceCleanup_NoWaitForTermination(t *testing.T) {
+	state := testState()
+	state.Put("publicKey", "key")
+
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	driver.WaitForInstanceStateErr = errors.New("error")
+	step.Cleanup(state)
+
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatalf("should not wait for termination by default")
+	}
+}
+
+func TestStepCreateInstance_UseInstance(t *testing.T) {
+	state := testState()
+	config := state.Get("config").(*Config)
+	config.UseInstanceID = "ocid1.instance.oc1.phx.existing"
+
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.CreateInstanceID != "" {
+		t.Fatalf("should not have launched an instance")
+	}
+	if id := state.Get("instance_id"); id != config.UseInstanceID {
+		t.Fatalf("Expected the existing instance to be used, got %v", id)
+	}
+
+	step.Cleanup(state)
+
+	if driver.TerminateInstanceID != "" {
+		t.Fatalf("should not have terminated the existing instance")
+	}
+}
+
+func TestStepCreateInstance_BaseImageFilter(t *testing.T) {
+	state := testState()
+	state.Put("publicKey", "key")
+
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
+	defer step.Cleanup(state)
+
+	config := state.Get("config").(*Config)
+	config.BaseImageID = ""
+
+	imageID := "ocid1.image.oc1.iad.newest"
+	driver := state.Get("driver").(*driverMock)
+	driver.ListImagesImages = []core.Image{{Id: &imageID}}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if config.BaseImageID != imageID {
+		t.Fatalf("should have resolved base image (%s != %s)", config.BaseImageID, imageID)
+	}
+}
+
+func TestStepCreateInstance_BaseImageFilterNoImages(t *testing.T) {
+	state := testState()
+	state.Put("publicKey", "key")
+
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
+	defer step.Cleanup(state)
+
+	config := state.Get("config").(*Config)
+	config.BaseImageID = ""
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+
+	if _, ok := state.GetOk("instance_id"); ok {
+		t.Fatalf("should NOT have instance_id")
+	}
+}
+
+func TestStepCreateInstance_SubnetName(t *testing.T) {
+	state := testState()
+	state.Put("publicKey", "key")
+
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
+	defer step.Cleanup(state)
+
+	config := state.Get("config").(*Config)
+	config.SubnetID = ""
+	config.SubnetName = "private"
+
+	subnetID := "ocid1.subnet.oc1.iad.private"
+	driver := state.Get("driver").(*driverMock)
+	driver.ListSubnetsSubnets = []core.Subnet{{Id: &subnetID}}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if config.SubnetID != subnetID {
+		t.Fatalf("should have resolved subnet (%s != %s)", config.SubnetID, subnetID)
+	}
+}
+
+func TestStepCreateInstance_SubnetNameAmbiguous(t *testing.T) {
+	state := testState()
+	state.Put("publicKey", "key")
+
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
+	defer step.Cleanup(state)
+
+	config := state.Get("config").(*Config)
+	config.SubnetID = ""
+	config.SubnetName = "private"
+
+	first, second := "ocid1.subnet.oc1.iad.first", "ocid1.subnet.oc1.iad.second"
+	driver := state.Get("driver").(*driverMock)
+	driver.ListSubnetsSubnets = []core.Subnet{{Id: &first}, {Id: &second}}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	err, ok := state.GetOk("error")
+	if !ok || !strings.Contains(err.(error).Error(), "matched 2 subnets") {
+		t.Fatalf("should have ambiguous subnet error, got %v", err)
+	}
+
+	if _, ok := state.GetOk("instance_id"); ok {
+		t.Fatalf("should NOT have instance_id")
+	}
+}
+
+func TestStepCreateInstance_DebugKeepInstance(t *testing.T) {
+	state := testState()
+	state.Put("publicKey", "key")
+	state.Get("config").(*Config).DebugKeepInstance = true
+
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	state.Put(multistep.StateHalted, true)
+	step.Cleanup(state)
+
+	if driver.TerminateInstanceID != "" {
+		t.Fatalf("Should not have terminated the instance of a failed build")
+	}
+}
+
+func TestStepCreateInstance_DebugKeepInstanceSuccess(t *testing.T) {
+	state := testState()
+	state.Put("publicKey", "key")
+	state.Get("config").(*Config).DebugKeepInstance = true
+
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	step.Cleanup(state)
+
+	if driver.TerminateInstanceID == "" {
+		t.Fatalf("Should have terminated the instance of a successful build")
+	}
+}
+
+func TestStepCreateInstance_SourceBootVolume(t *testing.T) {
+	state := testState()
+	state.Put("publicKey", "key")
+	config := state.Get("config").(*Config)
+	config.BaseImageID = ""
+	config.SourceBootVolumeID = "ocid1.bootvolume.oc1.iad.aaaa"
+
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+	driver.ListImagesErr = errors.New("error")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if config.BaseImageID != "" {
+		t.Fatalf("Should not have resolved a base image, got %s", config.BaseImageID)
+	}
+}
+
+func TestStepCreateInstance_WinRMOmitsSSHKey(t *testing.T) {
+	state := testState()
+	config := state.Get("config").(*Config)
+	config.Comm.Type = "winrm"
+	config.Comm.SSHPublicKey = []byte("ssh-rsa AAAA...")
+
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.CreateInstancePublicKey != "" {
+		t.Fatalf("Should not have passed an SSH key for WinRM, got %q", driver.CreateInstancePublicKey)
+	}
+}
+
+func TestStepCreateInstance_DedicatedVmHostAvailabilityDomainMismatch(t *testing.T) {
+	state := testState()
+	state.Put("publicKey", "key")
+	config := state.Get("config").(*Config)
+	config.DedicatedVmHostID = "ocid1.dedicatedvmhost.oc1.iad.aaaa"
+
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+	driver.GetDedicatedVmHostAvailabilityDomain = "aaaa:US-ASHBURN-AD-2"
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	out := state.Get("ui").(*packersdk.BasicUi).Writer.(*bytes.Buffer).String()
+	if !strings.Contains(out, "aaaa:US-ASHBURN-AD-2") {
+		t.Fatalf("Expected a warning about the dedicated VM host's availability domain, got %q", out)
+	}
+}
+
+func TestStepCreateInstance_SkipCreateImageKeepInstance(t *testing.T) {
+	state := testState()
+	state.Put("publicKey", "key")
+	config := state.Get("config").(*Config)
+	config.SkipCreateImage = true
+	config.DebugKeepInstance = true
+
+	step := &stepCreateInstance{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	step.Cleanup(state)
+
+	if driver.TerminateInstanceID != "" {
+		t.Fatalf("Should have kept the instance for inspection")
+	}
+}
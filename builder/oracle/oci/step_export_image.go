@@ -0,0 +1,45 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+type stepExportImage struct{}
+
+func (s *stepExportImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	var (
+		driver = state.Get("driver").(Driver)
+		ui     = state.Get("ui").(packersdk.Ui)
+		config = state.Get("config").(*Config)
+	)
+
+	if config.ImageExportBucket == "" {
+		return multistep.ActionContinue
+	}
+	image := state.Get("image").(core.Image)
+
+	ui.Say(fmt.Sprintf("Exporting image to bucket '%s'...", config.ImageExportBucket))
+
+	uri, err := driver.ExportImage(ctx, *image.Id)
+	if err != nil {
+		err = fmt.Errorf("Error exporting image: %s", err)
+		ui.Error(err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
+	state.Put("image_export_uri", uri)
+
+	ui.Say(fmt.Sprintf("Image exported to %s.", uri))
+
+	return multistep.ActionContinue
+}
+
+func (s *stepExportImage) Cleanup(state multistep.StateBag) {
+	// Nothing to do
+}
@@ -0,0 +1,84 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+func TestStepExportImage(t *testing.T) {
+	state := testState()
+	imageID := "ocid1.image"
+	state.Put("image", core.Image{Id: &imageID})
+
+	config := state.Get("config").(*Config)
+	config.ImageExportBucket = "bucket"
+	config.ImageExportNamespace = "namespace"
+	config.ImageExportName = "image"
+
+	step := new(stepExportImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.ExportImageID != imageID {
+		t.Fatalf("should have exported image (%s != %s)", driver.ExportImageID, imageID)
+	}
+
+	if _, ok := state.GetOk("image_export_uri"); !ok {
+		t.Fatalf("should have image_export_uri")
+	}
+}
+
+func TestStepExportImage_NotConfigured(t *testing.T) {
+	state := testState()
+	imageID := "ocid1.image"
+	state.Put("image", core.Image{Id: &imageID})
+
+	step := new(stepExportImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.ExportImageID != "" {
+		t.Fatalf("should not have exported image")
+	}
+
+	if _, ok := state.GetOk("image_export_uri"); ok {
+		t.Fatalf("should NOT have image_export_uri")
+	}
+}
+
+func TestStepExportImage_ExportImageErr(t *testing.T) {
+	state := testState()
+	imageID := "ocid1.image"
+	state.Put("image", core.Image{Id: &imageID})
+
+	config := state.Get("config").(*Config)
+	config.ImageExportBucket = "bucket"
+
+	step := new(stepExportImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+	driver.ExportImageErr = errors.New("error")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+}
@@ -0,0 +1,64 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+)
+
+func TestStepGetDefaultCredentials(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.Comm.Type = "winrm"
+
+	step := &stepGetDefaultCredentials{Comm: &config.Comm}
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if config.Comm.WinRMUser != "opc" || config.Comm.WinRMPassword != "password" {
+		t.Fatalf("Expected the initial credentials to be used, got %s/%s", config.Comm.WinRMUser, config.Comm.WinRMPassword)
+	}
+}
+
+func TestStepGetDefaultCredentials_SSH(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+
+	driver := state.Get("driver").(*driverMock)
+	driver.GetInstanceInitialCredentialsErr = errors.New("error")
+
+	step := &stepGetDefaultCredentials{Comm: &config.Comm}
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+}
+
+func TestStepGetDefaultCredentials_GetInstanceInitialCredentialsErr(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.Comm.Type = "winrm"
+
+	driver := state.Get("driver").(*driverMock)
+	driver.GetInstanceInitialCredentialsErr = errors.New("error")
+
+	step := &stepGetDefaultCredentials{Comm: &config.Comm}
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+}
@@ -12,11 +12,60 @@ type stepImage struct{}
 
 func (s *stepImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	var (
+		config     = state.Get("config").(*Config)
 		driver     = state.Get("driver").(Driver)
 		ui         = state.Get("ui").(packersdk.Ui)
 		instanceID = state.Get("instance_id").(string)
 	)
 
+	if config.SkipCreateImage {
+		ui.Say("Skipping image creation...")
+		return multistep.ActionContinue
+	}
+
+	if config.EphemeralPublicIP {
+		ui.Say("Removing the instance's public IP...")
+
+		if err := driver.RemovePublicIP(ctx, instanceID); err != nil {
+			err = fmt.Errorf("Error removing public IP from instance: %s", err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+	}
+
+	if config.StopBeforeImage {
+		ui.Say(fmt.Sprintf("Stopping instance (%s)...", instanceID))
+
+		if err := driver.StopInstance(ctx, instanceID); err != nil {
+			err = fmt.Errorf("Error stopping instance: %s", err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+
+		_, err := driver.WaitForInstanceState(ctx, instanceID, []string{"RUNNING", "STOPPING"}, []string{"STOPPED"})
+		if err != nil {
+			err = fmt.Errorf("Error waiting for instance to stop: %s", err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+	}
+
+	if config.InheritCompartmentTags {
+		ui.Say(fmt.Sprintf("Getting tag defaults of compartment (%s)...", config.ImageCompartmentID))
+
+		defaults, err := driver.ListTagDefaults(ctx, config.ImageCompartmentID)
+		if err != nil {
+			err = fmt.Errorf("Error getting tag defaults of image compartment: %s", err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+		config.ImageDefinedTags = mergeDefinedTags(defaults, config.ImageDefinedTags)
+	}
+
 	ui.Say("Creating image from instance...")
 
 	image, err := driver.CreateImage(ctx, instanceID)
@@ -35,11 +84,48 @@ func (s *stepImage) Run(ctx context.Context, state multistep.StateBag) multistep
 		return multistep.ActionHalt
 	}
 
-	// TODO(apryde): This is stale as .LifecycleState has changed to
-	// AVAILABLE at this point. Does it matter?
+	if config.ImageCapabilitySchemaID != "" {
+		ui.Say(fmt.Sprintf("Applying image capability schema (%s)...", config.ImageCapabilitySchemaID))
+
+		if _, err := driver.CopyImageCapabilitySchema(ctx, config.ImageCapabilitySchemaID, *image.Id); err != nil {
+			err = fmt.Errorf("Error applying image capability schema to image (%s): %s", *image.Id, err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+	}
+
+	if config.ImageOperatingSystem != "" {
+		ui.Say(fmt.Sprintf("Setting image operating system to %s %s...",
+			config.ImageOperatingSystem, config.ImageOperatingSystemVersion))
+
+		err = driver.SetImageOperatingSystem(ctx, *image.Id, config.ImageOperatingSystem, config.ImageOperatingSystemVersion)
+		if err != nil {
+			err = fmt.Errorf("Error setting operating system of image (%s): %s", *image.Id, err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+	}
+
+	// Refresh the image so that the artifact reflects the now AVAILABLE
+	// image rather than the PROVISIONING one returned on creation.
+	image, err = driver.GetImage(ctx, *image.Id)
+	if err != nil {
+		err = fmt.Errorf("Error getting created image: %s", err)
+		ui.Error(err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
 	state.Put("image", image)
 
-	ui.Say("Image created.")
+	// OCI only reports the size once the image is AVAILABLE.
+	if image.SizeInMBs != nil {
+		ui.Say(fmt.Sprintf("Image created (%d MB).", *image.SizeInMBs))
+	} else {
+		ui.Say("Image created.")
+	}
 
 	return multistep.ActionContinue
 }
@@ -47,3 +133,23 @@ func (s *stepImage) Run(ctx context.Context, state multistep.StateBag) multistep
 func (s *stepImage) Cleanup(state multistep.StateBag) {
 	// Nothing to do
 }
+
+// mergeDefinedTags merges sets of defined tags, with tags in later sets
+// taking precedence. It returns nil when there are no tags.
+func mergeDefinedTags(sets ...map[string]map[string]interface{}) map[string]map[string]interface{} {
+	var merged map[string]map[string]interface{}
+	for _, tags := range sets {
+		for namespace, values := range tags {
+			if merged == nil {
+				merged = map[string]map[string]interface{}{}
+			}
+			if merged[namespace] == nil {
+				merged[namespace] = map[string]interface{}{}
+			}
+			for key, value := range values {
+				merged[namespace][key] = value
+			}
+		}
+	}
+	return merged
+}
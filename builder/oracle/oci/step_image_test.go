@@ -1,11 +1,16 @@
 package oci
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/oracle/oci-go-sdk/core"
 )
 
 func TestStepImage(t *testing.T) {
@@ -69,3 +74,291 @@ func TestStepImage_WaitForImageCreationErr(t *testing.T) {
 		t.Fatalf("should not have image")
 	}
 }
+
+func TestStepImage_GetImageErr(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+
+	step := new(stepImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+	driver.GetImageErr = errors.New("error")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+
+	if _, ok := state.GetOk("image"); ok {
+		t.Fatalf("should not have image")
+	}
+}
+
+func TestStepImage_SkipCreateImage(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	state.Get("config").(*Config).SkipCreateImage = true
+
+	step := new(stepImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.CreateImageID != "" {
+		t.Fatalf("Should not have created an image")
+	}
+	if _, ok := state.GetOk("image"); ok {
+		t.Fatalf("should NOT have image")
+	}
+}
+
+func TestStepImage_ReportsSize(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+
+	step := new(stepImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+	driver.GetImageSizeInMBs = 4096
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	image := state.Get("image").(core.Image)
+	if image.SizeInMBs == nil || *image.SizeInMBs != 4096 {
+		t.Fatalf("Expected the image to have its size, got %v", image.SizeInMBs)
+	}
+
+	out := state.Get("ui").(*packersdk.BasicUi).Writer.(*bytes.Buffer).String()
+	if !strings.Contains(out, "Image created (4096 MB).") {
+		t.Fatalf("Expected the image size to be reported, got %q", out)
+	}
+}
+
+func TestStepImage_StopBeforeImage(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.StopBeforeImage = true
+
+	step := new(stepImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.StopInstanceID != "ocid1..." {
+		t.Fatalf("Expected the instance to be stopped, got %q", driver.StopInstanceID)
+	}
+}
+
+func TestStepImage_StopInstanceErr(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.StopBeforeImage = true
+
+	step := new(stepImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+	driver.StopInstanceErr = errors.New("error")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.CreateImageID != "" {
+		t.Fatalf("should not have created an image")
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+}
+
+func TestStepImage_EphemeralPublicIP(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.EphemeralPublicIP = true
+
+	step := new(stepImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.RemovePublicIPID != "ocid1..." {
+		t.Fatalf("Expected the instance's public IP to be removed, got %q", driver.RemovePublicIPID)
+	}
+}
+
+func TestStepImage_RemovePublicIPErr(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.EphemeralPublicIP = true
+
+	step := new(stepImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+	driver.RemovePublicIPErr = errors.New("error")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.CreateImageID != "" {
+		t.Fatalf("should not have created an image")
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+}
+
+func TestStepImage_InheritCompartmentTags(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.InheritCompartmentTags = true
+	config.ImageDefinedTags = map[string]map[string]interface{}{"Operations": {"CostCenter": "42"}}
+
+	driver := state.Get("driver").(*driverMock)
+	driver.ListTagDefaultsTags = map[string]map[string]interface{}{
+		"Operations": {"CostCenter": "1", "Team": "platform"},
+	}
+
+	step := new(stepImage)
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	expected := map[string]map[string]interface{}{"Operations": {"CostCenter": "42", "Team": "platform"}}
+	if !reflect.DeepEqual(config.ImageDefinedTags, expected) {
+		t.Fatalf("Expected image defined tags %v, got %v", expected, config.ImageDefinedTags)
+	}
+}
+
+func TestStepImage_ListTagDefaultsErr(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.InheritCompartmentTags = true
+
+	driver := state.Get("driver").(*driverMock)
+	driver.ListTagDefaultsErr = errors.New("error")
+
+	step := new(stepImage)
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+}
+
+func TestStepImage_ImageCapabilitySchema(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.ImageCapabilitySchemaID = "ocid1.computeimagecapabilityschema.oc1..schema"
+
+	step := new(stepImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.CopyImageCapabilitySchemaImageID != driver.CreateImageID {
+		t.Fatalf("Expected the schema to be applied to the image, got %q", driver.CopyImageCapabilitySchemaImageID)
+	}
+}
+
+func TestStepImage_OperatingSystem(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.ImageOperatingSystem = "Custom Linux"
+	config.ImageOperatingSystemVersion = "1.0"
+
+	step := new(stepImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.SetImageOperatingSystemID != driver.CreateImageID {
+		t.Fatalf("Expected the operating system to be set on the image, got %q", driver.SetImageOperatingSystemID)
+	}
+}
+
+func TestStepImage_SetImageOperatingSystemErr(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.ImageOperatingSystem = "Custom Linux"
+	config.ImageOperatingSystemVersion = "1.0"
+
+	step := new(stepImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+	driver.SetImageOperatingSystemErr = errors.New("error")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+}
+
+func TestStepImage_CopyImageCapabilitySchemaErr(t *testing.T) {
+	state := testState()
+	state.Put("instance_id", "ocid1...")
+	config := state.Get("config").(*Config)
+	config.ImageCapabilitySchemaID = "ocid1.computeimagecapabilityschema.oc1..schema"
+
+	step := new(stepImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+	driver.CopyImageCapabilitySchemaErr = errors.New("error")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+}
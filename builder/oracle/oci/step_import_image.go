@@ -0,0 +1,55 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// stepImportImage imports the base image from Object Storage when
+// base_image_import_bucket is set and launches the instance from it. The
+// imported image is kept so that later builds can use it as base_image_ocid.
+type stepImportImage struct{}
+
+func (s *stepImportImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	var (
+		driver = state.Get("driver").(Driver)
+		ui     = state.Get("ui").(packersdk.Ui)
+		config = state.Get("config").(*Config)
+	)
+
+	if config.BaseImageImportBucket == "" {
+		return multistep.ActionContinue
+	}
+
+	ui.Say(fmt.Sprintf("Importing base image '%s' from bucket '%s'...",
+		config.BaseImageImportName, config.BaseImageImportBucket))
+
+	image, err := driver.ImportImage(ctx)
+	if err != nil {
+		err = fmt.Errorf("Error importing base image: %s", err)
+		ui.Error(err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
+	ui.Say(fmt.Sprintf("Waiting for base image (%s) to be imported...", *image.Id))
+
+	if err := driver.WaitForImageCreation(ctx, *image.Id); err != nil {
+		err = fmt.Errorf("Error waiting for base image import: %s", err)
+		ui.Error(err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
+	config.BaseImageID = *image.Id
+	ui.Say(fmt.Sprintf("Imported base image (%s).", config.BaseImageID))
+
+	return multistep.ActionContinue
+}
+
+func (s *stepImportImage) Cleanup(state multistep.StateBag) {
+	// Nothing to do
+}
@@ -0,0 +1,76 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+)
+
+func TestStepImportImage(t *testing.T) {
+	state := testState()
+
+	config := state.Get("config").(*Config)
+	config.BaseImageID = ""
+	config.BaseImageImportBucket = "bucket"
+	config.BaseImageImportNamespace = "namespace"
+	config.BaseImageImportName = "image"
+
+	step := new(stepImportImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.ImportImageID == "" {
+		t.Fatalf("should have imported image")
+	}
+
+	if config.BaseImageID != driver.ImportImageID {
+		t.Fatalf("should launch from the imported image (%s != %s)", config.BaseImageID, driver.ImportImageID)
+	}
+}
+
+func TestStepImportImage_NotConfigured(t *testing.T) {
+	state := testState()
+
+	step := new(stepImportImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.ImportImageID != "" {
+		t.Fatalf("should not have imported image")
+	}
+}
+
+func TestStepImportImage_WaitErr(t *testing.T) {
+	state := testState()
+
+	config := state.Get("config").(*Config)
+	config.BaseImageImportBucket = "bucket"
+	config.BaseImageImportNamespace = "namespace"
+	config.BaseImageImportName = "image"
+
+	step := new(stepImportImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+	driver.WaitForImageCreationErr = errors.New("error")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+}
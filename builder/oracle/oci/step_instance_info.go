@@ -6,9 +6,12 @@ import (
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/packerbuilderdata"
 )
 
-type stepInstanceInfo struct{}
+type stepInstanceInfo struct {
+	GeneratedData *packerbuilderdata.GeneratedData
+}
 
 func (s *stepInstanceInfo) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	var (
@@ -26,6 +29,7 @@ func (s *stepInstanceInfo) Run(ctx context.Context, state multistep.StateBag) mu
 	}
 
 	state.Put("instance_ip", ip)
+	s.GeneratedData.Put("InstanceIP", ip)
 
 	ui.Say(fmt.Sprintf("Instance has IP: %s.", ip))
 
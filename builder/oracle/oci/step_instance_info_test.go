@@ -8,13 +8,14 @@ import (
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/packerbuilderdata"
 )
 
 func TestInstanceInfo(t *testing.T) {
 	state := testState()
 	state.Put("instance_id", "ocid1...")
 
-	step := new(stepInstanceInfo)
+	step := &stepInstanceInfo{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
 	defer step.Cleanup(state)
 
 	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
@@ -29,6 +30,11 @@ func TestInstanceInfo(t *testing.T) {
 	if instanceIPRaw.(string) != "ip" {
 		t.Fatalf("should've got ip ('%s' != 'ip')", instanceIPRaw.(string))
 	}
+
+	generatedData := state.Get("generated_data").(map[string]interface{})
+	if generatedData["InstanceIP"] != "ip" {
+		t.Fatalf("should've exposed the ip as generated data, got %v", generatedData["InstanceIP"])
+	}
 }
 
 func TestInstanceInfoPrivateIP(t *testing.T) {
@@ -44,7 +50,7 @@ func TestInstanceInfoPrivateIP(t *testing.T) {
 	})
 	state.Put("instance_id", "ocid1...")
 
-	step := new(stepInstanceInfo)
+	step := &stepInstanceInfo{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
 	defer step.Cleanup(state)
 
 	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
@@ -65,7 +71,7 @@ func TestInstanceInfo_GetInstanceIPErr(t *testing.T) {
 	state := testState()
 	state.Put("instance_id", "ocid1...")
 
-	step := new(stepInstanceInfo)
+	step := &stepInstanceInfo{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
 	defer step.Cleanup(state)
 
 	driver := state.Get("driver").(*driverMock)
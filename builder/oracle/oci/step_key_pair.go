@@ -0,0 +1,35 @@
+package oci
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	ocommon "github.com/hashicorp/packer/builder/oracle/common"
+)
+
+// stepKeyPair is the common key pair step, except that the temporary key
+// saved for debugging is removed once the build is over. It is kept along
+// with an instance kept by debug_keep_instance, so that it can be logged
+// into.
+type stepKeyPair struct {
+	ocommon.StepKeyPair
+}
+
+func (s *stepKeyPair) Cleanup(state multistep.StateBag) {
+	// A key provided with 'ssh_private_key_file' is never written out.
+	if !s.Debug || s.Comm.SSHPrivateKeyFile != "" {
+		return
+	}
+
+	ui := state.Get("ui").(packersdk.Ui)
+	if keepInstance(state) {
+		ui.Message(fmt.Sprintf("Keeping debug key for the instance: %s", s.DebugKeyPath))
+		return
+	}
+
+	if err := os.Remove(s.DebugKeyPath); err != nil && !os.IsNotExist(err) {
+		ui.Error(fmt.Sprintf("Error removing debug key '%s': %s", s.DebugKeyPath, err))
+	}
+}
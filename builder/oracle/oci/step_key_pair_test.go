@@ -0,0 +1,57 @@
+package oci
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/communicator"
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	ocommon "github.com/hashicorp/packer/builder/oracle/common"
+)
+
+func TestStepKeyPair_Cleanup(t *testing.T) {
+	for name, tc := range map[string]struct {
+		keepInstance bool
+		halted       bool
+		removed      bool
+	}{
+		"Succeeded":    {false, false, true},
+		"Failed":       {false, true, true},
+		"KeptInstance": {true, true, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "packer_key_pair")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			state := testState()
+			state.Get("config").(*Config).DebugKeepInstance = tc.keepInstance
+			if tc.halted {
+				state.Put(multistep.StateHalted, true)
+			}
+
+			path := filepath.Join(dir, "debug.pem")
+			step := &stepKeyPair{
+				StepKeyPair: ocommon.StepKeyPair{Debug: true, Comm: &communicator.Config{}, DebugKeyPath: path},
+			}
+
+			if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+				t.Fatalf("bad action: %#v", action)
+			}
+			step.Cleanup(state)
+
+			_, err = os.Stat(path)
+			if tc.removed && !os.IsNotExist(err) {
+				t.Errorf("Expected the debug key to be removed, got %v", err)
+			}
+			if !tc.removed && err != nil {
+				t.Errorf("Expected the debug key to be kept, got %v", err)
+			}
+		})
+	}
+}
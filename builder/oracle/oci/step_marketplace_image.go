@@ -0,0 +1,88 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// stepMarketplaceImage resolves the image of the Marketplace listing when
+// marketplace_listing_ocid is set and launches the instance from it,
+// subscribing to the listing first when marketplace_accept_agreement is set.
+type stepMarketplaceImage struct{}
+
+func (s *stepMarketplaceImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	var (
+		driver = state.Get("driver").(Driver)
+		ui     = state.Get("ui").(packersdk.Ui)
+		config = state.Get("config").(*Config)
+	)
+
+	if config.MarketplaceListingID == "" {
+		return multistep.ActionContinue
+	}
+
+	ui.Say(fmt.Sprintf("Resolving image of Marketplace listing (%s)...", config.MarketplaceListingID))
+
+	version, err := driver.GetMarketplaceListingVersion(ctx)
+	if err != nil {
+		err = fmt.Errorf("Error getting Marketplace listing: %s", err)
+		ui.Error(err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+	if version.ListingResourceId == nil || version.ListingResourceVersion == nil {
+		err = fmt.Errorf("Marketplace listing %s has no image", config.MarketplaceListingID)
+		ui.Error(err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
+	// The image is created from a snapshot of the instance's boot volume,
+	// which some publishers don't allow.
+	snapshot := false
+	for _, action := range version.AllowedActions {
+		snapshot = snapshot || action == core.AppCatalogListingResourceVersionAllowedActionsSnapshot
+	}
+	if !snapshot {
+		err = fmt.Errorf(
+			"Marketplace listing %s version %s does not allow images to be created from its instances",
+			config.MarketplaceListingID, *version.ListingResourceVersion)
+		ui.Error(err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
+	if len(version.CompatibleShapes) > 0 && !stringSliceContains(version.CompatibleShapes, config.Shape) {
+		err = fmt.Errorf(
+			"Marketplace listing %s version %s does not support shape %s",
+			config.MarketplaceListingID, *version.ListingResourceVersion, config.Shape)
+		ui.Error(err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
+	if config.MarketplaceAcceptAgreement {
+		ui.Say(fmt.Sprintf("Accepting the terms of Marketplace listing version %s...", *version.ListingResourceVersion))
+
+		if err := driver.SubscribeMarketplaceListing(ctx, *version.ListingResourceVersion); err != nil {
+			err = fmt.Errorf("Error subscribing to Marketplace listing: %s", err)
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+	}
+
+	config.BaseImageID = *version.ListingResourceId
+	ui.Say(fmt.Sprintf("Using base image (%s) of Marketplace listing version %s.",
+		config.BaseImageID, *version.ListingResourceVersion))
+
+	return multistep.ActionContinue
+}
+
+func (s *stepMarketplaceImage) Cleanup(state multistep.StateBag) {
+	// Nothing to do
+}
@@ -0,0 +1,127 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+func TestStepMarketplaceImage(t *testing.T) {
+	state := testState()
+
+	config := state.Get("config").(*Config)
+	config.BaseImageID = ""
+	config.MarketplaceListingID = "ocid1.appcataloglisting.oc1..aaaa"
+	config.MarketplaceAcceptAgreement = true
+
+	step := new(stepMarketplaceImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.SubscribeMarketplaceListingVersion != "1.0" {
+		t.Fatalf("should have subscribed to the listing version, got %q", driver.SubscribeMarketplaceListingVersion)
+	}
+
+	if config.BaseImageID != "ocid1.image.oc1..marketplace" {
+		t.Fatalf("should launch from the listing's image, got %s", config.BaseImageID)
+	}
+}
+
+func TestStepMarketplaceImage_NotConfigured(t *testing.T) {
+	state := testState()
+
+	config := state.Get("config").(*Config)
+	baseImageID := config.BaseImageID
+
+	step := new(stepMarketplaceImage)
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if config.BaseImageID != baseImageID {
+		t.Fatalf("should not have changed the base image, got %s", config.BaseImageID)
+	}
+}
+
+func TestStepMarketplaceImage_NotAccepted(t *testing.T) {
+	state := testState()
+
+	config := state.Get("config").(*Config)
+	config.BaseImageID = ""
+	config.MarketplaceListingID = "ocid1.appcataloglisting.oc1..aaaa"
+
+	step := new(stepMarketplaceImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.SubscribeMarketplaceListingVersion != "" {
+		t.Fatalf("should not have subscribed without marketplace_accept_agreement")
+	}
+}
+
+func TestStepMarketplaceImage_Errors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		setup func(*driverMock)
+		err   string
+	}{
+		"Get": {
+			func(d *driverMock) { d.GetMarketplaceListingVersionErr = errors.New("error") },
+			"Error getting Marketplace listing",
+		},
+		"NoSnapshot": {
+			func(d *driverMock) {
+				d.GetMarketplaceListingVersionAllowedActions = []core.AppCatalogListingResourceVersionAllowedActionsEnum{
+					core.AppCatalogListingResourceVersionAllowedActionsSerialConsoleAccess,
+				}
+			},
+			"does not allow images to be created",
+		},
+		"Shape": {
+			func(d *driverMock) { d.GetMarketplaceListingVersionShapes = []string{"VM.Standard2.1"} },
+			"does not support shape VM.Standard1.1",
+		},
+		"Subscribe": {
+			func(d *driverMock) { d.SubscribeMarketplaceListingErr = errors.New("error") },
+			"Error subscribing to Marketplace listing",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			state := testState()
+
+			config := state.Get("config").(*Config)
+			config.BaseImageID = ""
+			config.MarketplaceListingID = "ocid1.appcataloglisting.oc1..aaaa"
+			config.MarketplaceAcceptAgreement = true
+
+			step := new(stepMarketplaceImage)
+			defer step.Cleanup(state)
+
+			tc.setup(state.Get("driver").(*driverMock))
+
+			if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+				t.Fatalf("bad action: %#v", action)
+			}
+
+			err, ok := state.GetOk("error")
+			if !ok || !strings.Contains(err.(error).Error(), tc.err) {
+				t.Fatalf("should have error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// stepMoveImage moves the image to final_image_compartment_ocid. It is the
+// last step, so an image from a failed build stays where it was created.
+type stepMoveImage struct{}
+
+func (s *stepMoveImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	var (
+		driver = state.Get("driver").(Driver)
+		ui     = state.Get("ui").(packersdk.Ui)
+		config = state.Get("config").(*Config)
+	)
+
+	if config.FinalImageCompartmentID == "" || config.FinalImageCompartmentID == config.ImageCompartmentID {
+		return multistep.ActionContinue
+	}
+	image := state.Get("image").(core.Image)
+
+	ui.Say(fmt.Sprintf("Moving image to final compartment (%s)...", config.FinalImageCompartmentID))
+
+	err := driver.ChangeImageCompartment(ctx, *image.Id, config.FinalImageCompartmentID)
+	if err != nil {
+		err = fmt.Errorf("Error moving image (%s) to final_image_compartment_ocid, it remains in compartment %s: %s",
+			*image.Id, config.ImageCompartmentID, err)
+		ui.Error(err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
+	image.CompartmentId = &config.FinalImageCompartmentID
+	state.Put("image", image)
+
+	return multistep.ActionContinue
+}
+
+func (s *stepMoveImage) Cleanup(state multistep.StateBag) {
+	// Nothing to do
+}
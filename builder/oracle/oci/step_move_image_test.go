@@ -0,0 +1,82 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+func TestStepMoveImage(t *testing.T) {
+	state := testState()
+	imageID := "ocid1.image"
+	state.Put("image", core.Image{Id: &imageID})
+
+	config := state.Get("config").(*Config)
+	config.FinalImageCompartmentID = "ocid1.compartment.oc1..final"
+
+	step := new(stepMoveImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.ChangeImageCompartmentID != imageID {
+		t.Fatalf("should have moved image (%s != %s)", driver.ChangeImageCompartmentID, imageID)
+	}
+	if driver.ChangeImageCompartmentCompartmentID != config.FinalImageCompartmentID {
+		t.Fatalf("bad compartment: %s", driver.ChangeImageCompartmentCompartmentID)
+	}
+
+	image := state.Get("image").(core.Image)
+	if image.CompartmentId == nil || *image.CompartmentId != config.FinalImageCompartmentID {
+		t.Fatalf("image should record its final compartment, got %v", image.CompartmentId)
+	}
+}
+
+func TestStepMoveImage_NotConfigured(t *testing.T) {
+	state := testState()
+	imageID := "ocid1.image"
+	state.Put("image", core.Image{Id: &imageID})
+
+	step := new(stepMoveImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if driver.ChangeImageCompartmentID != "" {
+		t.Fatalf("should not have moved image")
+	}
+}
+
+func TestStepMoveImage_ChangeImageCompartmentErr(t *testing.T) {
+	state := testState()
+	imageID := "ocid1.image"
+	state.Put("image", core.Image{Id: &imageID})
+
+	config := state.Get("config").(*Config)
+	config.FinalImageCompartmentID = "ocid1.compartment.oc1..final"
+
+	step := new(stepMoveImage)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*driverMock)
+	driver.ChangeImageCompartmentErr = errors.New("error")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+}
@@ -0,0 +1,52 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// stepValidateAvailabilityDomain checks that availability_domain is one of
+// the region's before anything is launched, as a typo otherwise only fails
+// the launch.
+type stepValidateAvailabilityDomain struct{}
+
+func (s *stepValidateAvailabilityDomain) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	var (
+		driver = state.Get("driver").(Driver)
+		ui     = state.Get("ui").(packersdk.Ui)
+		config = state.Get("config").(*Config)
+	)
+
+	if config.SkipAvailabilityDomainValidation {
+		return multistep.ActionContinue
+	}
+
+	ui.Say("Validating availability domain...")
+
+	names, err := driver.ListAvailabilityDomains(ctx)
+	if err != nil {
+		err = fmt.Errorf("Error listing availability domains, set skip_availability_domain_validation to skip this check: %s", err)
+		ui.Error(err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+	for _, name := range names {
+		if name == config.AvailabilityDomain {
+			return multistep.ActionContinue
+		}
+	}
+
+	err = fmt.Errorf("Availability domain %q not found, must be one of: %s",
+		config.AvailabilityDomain, strings.Join(names, ", "))
+	ui.Error(err.Error())
+	state.Put("error", err)
+	return multistep.ActionHalt
+}
+
+func (s *stepValidateAvailabilityDomain) Cleanup(state multistep.StateBag) {
+	// Nothing to do
+}
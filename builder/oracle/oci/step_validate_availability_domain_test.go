@@ -0,0 +1,56 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+)
+
+func TestStepValidateAvailabilityDomain(t *testing.T) {
+	state := testState()
+
+	step := new(stepValidateAvailabilityDomain)
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+}
+
+func TestStepValidateAvailabilityDomain_NotFound(t *testing.T) {
+	state := testState()
+
+	driver := state.Get("driver").(*driverMock)
+	driver.ListAvailabilityDomainsNames = []string{"aaaa:US-ASHBURN-AD-2", "aaaa:US-ASHBURN-AD-3"}
+
+	step := new(stepValidateAvailabilityDomain)
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	err, ok := state.GetOk("error")
+	if !ok || !strings.Contains(err.(error).Error(), "aaaa:US-ASHBURN-AD-2, aaaa:US-ASHBURN-AD-3") {
+		t.Fatalf("should have error listing the valid availability domains, got %v", err)
+	}
+}
+
+func TestStepValidateAvailabilityDomain_Skip(t *testing.T) {
+	state := testState()
+	config := state.Get("config").(*Config)
+	config.SkipAvailabilityDomainValidation = true
+
+	driver := state.Get("driver").(*driverMock)
+	driver.ListAvailabilityDomainsErr = errors.New("not authorized")
+
+	step := new(stepValidateAvailabilityDomain)
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+}
@@ -0,0 +1,76 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// stepValidateTagNamespaces checks that the namespaces of the defined tags
+// exist before anything is launched, rather than failing when the image is
+// created.
+type stepValidateTagNamespaces struct{}
+
+func (s *stepValidateTagNamespaces) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	var (
+		driver = state.Get("driver").(Driver)
+		ui     = state.Get("ui").(packersdk.Ui)
+		config = state.Get("config").(*Config)
+	)
+
+	if config.SkipTagValidation {
+		return multistep.ActionContinue
+	}
+
+	required := map[string]bool{}
+	for namespace := range config.ImageDefinedTags {
+		required[namespace] = true
+	}
+	for namespace := range config.InstanceDefinedTags {
+		required[namespace] = true
+	}
+	for _, volume := range config.BlockVolumes {
+		for namespace := range volume.DefinedTags {
+			required[namespace] = true
+		}
+	}
+	if len(required) == 0 {
+		return multistep.ActionContinue
+	}
+
+	ui.Say("Validating defined tag namespaces...")
+
+	namespaces, err := driver.ListTagNamespaces(ctx)
+	if err != nil {
+		err = fmt.Errorf("Error listing tag namespaces, set skip_tag_validation to skip this check: %s", err)
+		ui.Error(err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+	for _, namespace := range namespaces {
+		delete(required, namespace)
+	}
+
+	if len(required) > 0 {
+		var missing []string
+		for namespace := range required {
+			missing = append(missing, namespace)
+		}
+		sort.Strings(missing)
+
+		err = fmt.Errorf("Defined tag namespaces not found: %s", strings.Join(missing, ", "))
+		ui.Error(err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepValidateTagNamespaces) Cleanup(state multistep.StateBag) {
+	// Nothing to do
+}
@@ -0,0 +1,108 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+)
+
+func TestStepValidateTagNamespaces(t *testing.T) {
+	state := testState()
+	config := state.Get("config").(*Config)
+	config.ImageDefinedTags = map[string]map[string]interface{}{"Operations": {"CostCenter": "42"}}
+	config.InstanceDefinedTags = map[string]map[string]interface{}{"Build": {"Tool": "packer"}}
+
+	driver := state.Get("driver").(*driverMock)
+	driver.ListTagNamespacesNames = []string{"Build", "Operations"}
+
+	step := new(stepValidateTagNamespaces)
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+}
+
+func TestStepValidateTagNamespaces_Missing(t *testing.T) {
+	state := testState()
+	config := state.Get("config").(*Config)
+	config.ImageDefinedTags = map[string]map[string]interface{}{"Operatoins": {"CostCenter": "42"}}
+
+	driver := state.Get("driver").(*driverMock)
+	driver.ListTagNamespacesNames = []string{"Operations"}
+
+	step := new(stepValidateTagNamespaces)
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	err, ok := state.GetOk("error")
+	if !ok || !strings.Contains(err.(error).Error(), "Operatoins") {
+		t.Fatalf("should have error naming the missing namespace, got %v", err)
+	}
+}
+
+func TestStepValidateTagNamespaces_BlockVolume(t *testing.T) {
+	state := testState()
+	config := state.Get("config").(*Config)
+	config.BlockVolumes = []BlockVolume{
+		{SizeInGBs: 50, DefinedTags: map[string]map[string]interface{}{"Storage": {"Tier": "gold"}}},
+	}
+
+	driver := state.Get("driver").(*driverMock)
+	driver.ListTagNamespacesNames = []string{"Operations"}
+
+	step := new(stepValidateTagNamespaces)
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	err, ok := state.GetOk("error")
+	if !ok || !strings.Contains(err.(error).Error(), "Storage") {
+		t.Fatalf("should have error naming the missing namespace, got %v", err)
+	}
+}
+
+func TestStepValidateTagNamespaces_Skip(t *testing.T) {
+	state := testState()
+	config := state.Get("config").(*Config)
+	config.ImageDefinedTags = map[string]map[string]interface{}{"Operations": {"CostCenter": "42"}}
+	config.SkipTagValidation = true
+
+	driver := state.Get("driver").(*driverMock)
+	driver.ListTagNamespacesErr = errors.New("error")
+
+	step := new(stepValidateTagNamespaces)
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+}
+
+func TestStepValidateTagNamespaces_ListTagNamespacesErr(t *testing.T) {
+	state := testState()
+	config := state.Get("config").(*Config)
+	config.ImageDefinedTags = map[string]map[string]interface{}{"Operations": {"CostCenter": "42"}}
+
+	driver := state.Get("driver").(*driverMock)
+	driver.ListTagNamespacesErr = errors.New("error")
+
+	step := new(stepValidateTagNamespaces)
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+}
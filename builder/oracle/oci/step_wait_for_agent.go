@@ -0,0 +1,94 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// agentWaitTimeout bounds the wait for the communicator port when
+// state_timeout is not set.
+const agentWaitTimeout = 10 * time.Minute
+
+// stepWaitForAgent waits for the communicator port of a RUNNING instance to
+// accept connections, as sshd or WinRM may not be listening yet.
+type stepWaitForAgent struct {
+	// dial connects to the instance, defaulting to a net.Dialer.
+	dial func(ctx context.Context, network, address string) (net.Conn, error)
+	// timeout bounds the wait when state_timeout is not set, defaulting to
+	// agentWaitTimeout.
+	timeout time.Duration
+}
+
+func (s *stepWaitForAgent) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	var (
+		config = state.Get("config").(*Config)
+		ui     = state.Get("ui").(packersdk.Ui)
+	)
+
+	if !config.WaitForAgent || config.Comm.Type == "none" {
+		return multistep.ActionContinue
+	}
+
+	// The instance may only be reachable through the bastion, which the
+	// communicator connects through and retries on its own.
+	if config.Comm.Type == "ssh" && config.Comm.SSHBastionHost != "" {
+		log.Printf("[INFO] Not waiting for the instance to accept connections as it is reached through %s", config.Comm.SSHBastionHost)
+		return multistep.ActionContinue
+	}
+
+	host := config.Comm.Host()
+	if host == "" {
+		host = state.Get("instance_ip").(string)
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(config.Comm.Port()))
+
+	dial := s.dial
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: config.StatePollInterval}).DialContext
+	}
+
+	ui.Say(fmt.Sprintf("Waiting for instance to accept connections on %s...", address))
+
+	timeout := config.StateTimeout
+	if timeout <= 0 {
+		timeout = s.timeout
+		if timeout <= 0 {
+			timeout = agentWaitTimeout
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		conn, err := dial(ctx, "tcp", address)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		log.Printf("[DEBUG] Instance not accepting connections on %s yet: %s", address, err)
+
+		select {
+		case <-ctx.Done():
+			err = fmt.Errorf("Error waiting for instance to accept connections on %s: %s", address, ctx.Err())
+			ui.Error(err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		case <-time.After(config.StatePollInterval):
+		}
+	}
+
+	ui.Say("Instance accepting connections.")
+
+	return multistep.ActionContinue
+}
+
+func (s *stepWaitForAgent) Cleanup(state multistep.StateBag) {
+	// no cleanup
+}
@@ -0,0 +1,132 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+)
+
+func TestStepWaitForAgent(t *testing.T) {
+	state := testState()
+	state.Put("instance_ip", "192.0.2.1")
+	config := state.Get("config").(*Config)
+	config.WaitForAgent = true
+	config.StatePollInterval = time.Millisecond
+
+	attempts := 0
+	var dialed string
+	step := &stepWaitForAgent{
+		dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			attempts++
+			dialed = address
+			if attempts < 3 {
+				return nil, errors.New("connection refused")
+			}
+			client, server := net.Pipe()
+			server.Close()
+			return client, nil
+		},
+	}
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if attempts != 3 {
+		t.Fatalf("Expected to dial until the instance accepted connections, dialed %d times", attempts)
+	}
+	if dialed != "192.0.2.1:22" {
+		t.Fatalf("Expected to dial the SSH port of the instance, dialed %s", dialed)
+	}
+}
+
+func TestStepWaitForAgent_Timeout(t *testing.T) {
+	state := testState()
+	state.Put("instance_ip", "192.0.2.1")
+	config := state.Get("config").(*Config)
+	config.WaitForAgent = true
+	config.StatePollInterval = time.Millisecond
+	config.StateTimeout = 10 * time.Millisecond
+
+	step := &stepWaitForAgent{
+		dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+}
+
+func TestStepWaitForAgent_DefaultTimeout(t *testing.T) {
+	state := testState()
+	state.Put("instance_ip", "192.0.2.1")
+	config := state.Get("config").(*Config)
+	config.WaitForAgent = true
+	config.StatePollInterval = time.Millisecond
+	config.StateTimeout = 0
+
+	step := &stepWaitForAgent{
+		dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		},
+		timeout: 10 * time.Millisecond,
+	}
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatalf("should have error")
+	}
+}
+
+func TestStepWaitForAgent_Bastion(t *testing.T) {
+	state := testState()
+	state.Put("instance_ip", "10.0.0.2")
+	config := state.Get("config").(*Config)
+	config.WaitForAgent = true
+	config.UsePrivateIP = true
+	config.Comm.SSHBastionHost = "bastion.example.com"
+
+	step := &stepWaitForAgent{
+		dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			t.Fatalf("Should not have dialed %s", address)
+			return nil, nil
+		},
+	}
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+}
+
+func TestStepWaitForAgent_Disabled(t *testing.T) {
+	state := testState()
+
+	step := &stepWaitForAgent{
+		dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			t.Fatalf("Should not have dialed %s", address)
+			return nil, nil
+		},
+	}
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+}